@@ -77,7 +77,7 @@ if [ ! -f "go.sum" ]; then
     go mod tidy
 fi
 
-go build -o tracery-cli main.go
+go build -o tracery-cli .
 echo "CLI built: dcdot-cli/dcdot-cli"
 cd ..
 
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+)
+
+const (
+	defaultAlertBreakpointTTL = 15 * time.Minute
+	maxAlertBreakpointTTL     = time.Hour
+
+	// Alert labels with this prefix become breakpoint conditions, e.g.
+	// tracery_condition_customer_id="CUST-123" -> customer_id=CUST-123.
+	alertConditionLabelPrefix = "tracery_condition_"
+)
+
+// alertmanagerWebhook is the subset of Alertmanager's webhook payload (v4)
+// we need.
+type alertmanagerWebhook struct {
+	Version string              `json:"version"`
+	Status  string              `json:"status"`
+	Alerts  []alertmanagerAlert `json:"alerts"`
+}
+
+type alertmanagerAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+// handleAlertmanagerWebhook arms a one-shot breakpoint for every firing
+// alert so the next matching trace after an alert is captured, and removes
+// it again when the alert resolves. Breakpoints are named after the alert
+// fingerprint, so repeated notifications for one alert refresh a single
+// breakpoint instead of piling up new ones.
+//
+// Anyone who can reach the webhook can arm breakpoints, so it is disabled
+// unless TRACERY_ALERT_WEBHOOK_TOKEN is set, and Alertmanager must send that
+// token as a bearer token (http_config.authorization in its receiver).
+func (s *ControlPlaneServer) handleAlertmanagerWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.alertWebhookToken == "" {
+		http.Error(w, "alert webhook disabled: TRACERY_ALERT_WEBHOOK_TOKEN is not set", http.StatusNotFound)
+		return
+	}
+	if !validBearerToken(r, s.alertWebhookToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var payload alertmanagerWebhook
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid webhook payload", http.StatusBadRequest)
+		return
+	}
+
+	armed, removed, skipped := 0, 0, 0
+	for _, alert := range payload.Alerts {
+		name := "alert-" + alert.Fingerprint
+		if alert.Fingerprint == "" {
+			skipped++
+			continue
+		}
+
+		if alert.Status == "resolved" {
+			resp, _ := s.DeleteBreakPoint(r.Context(), &pb.DeleteBreakPointRequest{BreakpointId: name})
+			if resp.GetSuccess() {
+				removed++
+			}
+			continue
+		}
+
+		service := firstLabel(alert.Labels, "service", "service_name", "job")
+		if service == "" {
+			log.Printf("[ControlPlane] Ignoring alert %s (%s): no service label", alert.Fingerprint, alert.Labels["alertname"])
+			skipped++
+			continue
+		}
+
+		conditions := make(map[string]string)
+		for k, v := range alert.Labels {
+			if strings.HasPrefix(k, alertConditionLabelPrefix) {
+				conditions[strings.TrimPrefix(k, alertConditionLabelPrefix)] = v
+			}
+		}
+
+		ttl := alertBreakpointTTL(alert)
+		resp, err := s.RegisterBreakpoint(r.Context(), &pb.RegisterBreakPointRequest{
+			Name:        name,
+			ServiceName: service,
+			Endpoint:    firstLabel(alert.Labels, "endpoint", "route", "http_route"),
+			Conditions:  conditions,
+			MaxHits:     1,
+			TtlSeconds:  int64(ttl.Round(time.Second).Seconds()),
+		})
+		if err != nil || !resp.GetSuccess() {
+			skipped++
+			continue
+		}
+
+		log.Printf("[ControlPlane] Alert %s armed breakpoint %s for %s", alert.Labels["alertname"], resp.GetBreakpointId(), ttl)
+		armed++
+	}
+
+	writeJSON(w, http.StatusOK, map[string]int{
+		"armed":   armed,
+		"removed": removed,
+		"skipped": skipped,
+	})
+}
+
+// alertBreakpointTTL derives how long an alert's breakpoint stays armed.
+// Alertmanager sets endsAt on firing alerts to when it will consider them
+// resolved if no update arrives, which is a good bound for the breakpoint.
+func alertBreakpointTTL(alert alertmanagerAlert) time.Duration {
+	ttl := defaultAlertBreakpointTTL
+	if !alert.EndsAt.IsZero() && alert.EndsAt.After(alert.StartsAt) {
+		ttl = time.Until(alert.EndsAt)
+	}
+	if ttl <= 0 {
+		ttl = defaultAlertBreakpointTTL
+	}
+	if ttl > maxAlertBreakpointTTL {
+		ttl = maxAlertBreakpointTTL
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+// validBearerToken reports whether r carries "Authorization: Bearer <token>".
+func validBearerToken(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func firstLabel(labels map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := labels[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testAlertBody = `{"version": "4", "status": "firing", "alerts": [{"status": "firing", "fingerprint": "abc123", "labels": {"alertname": "HighErrorRate", "service": "order-processing"}}]}`
+
+func postAlert(s *ControlPlaneServer, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/alertmanager", strings.NewReader(testAlertBody))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	s.handleAlertmanagerWebhook(rec, req)
+	return rec
+}
+
+func TestAlertBreakpointCapturesOneTrace(t *testing.T) {
+	s := NewControlPlaneServer(nil, nil)
+	s.alertWebhookToken = "s3cret"
+
+	rec := postAlert(s, "Bearer s3cret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	bp := s.breakpointByName("alert-abc123")
+	if bp == nil {
+		t.Fatal("no breakpoint armed for the alert")
+	}
+	if bp.MaxHits != 1 || bp.ExpiresAt.IsZero() {
+		t.Errorf("MaxHits = %d, ExpiresAt = %v; want a one-shot breakpoint with a TTL", bp.MaxHits, bp.ExpiresAt)
+	}
+}
+
+func TestAlertWebhookRequiresToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{"no token configured", "", "Bearer s3cret", http.StatusNotFound},
+		{"missing header", "s3cret", "", http.StatusUnauthorized},
+		{"wrong token", "s3cret", "Bearer guess", http.StatusUnauthorized},
+		{"not a bearer token", "s3cret", "Basic s3cret", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewControlPlaneServer(nil, nil)
+			s.alertWebhookToken = tt.token
+
+			if rec := postAlert(s, tt.authorization); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if s.breakpointByName("alert-abc123") != nil {
+				t.Error("rejected webhook armed a breakpoint")
+			}
+		})
+	}
+}
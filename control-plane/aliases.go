@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// aliasAttribute turns a span attribute into a trace alias, so a trace can
+// be looked up by, say, its order ID without anyone calling
+// RegisterTraceAlias.
+type aliasAttribute struct {
+	Key    string // span attribute to read
+	Prefix string // prepended to the value, e.g. "order:"
+}
+
+// aliasAttributesFromEnv reads TRACERY_ALIAS_ATTRIBUTES, a comma-separated
+// list of span attributes with an optional alias prefix, e.g.
+// "order_id=order:,order.id=order:". Services that record the same key
+// under different attribute names can share a prefix.
+func aliasAttributesFromEnv() ([]aliasAttribute, error) {
+	v := os.Getenv("TRACERY_ALIAS_ATTRIBUTES")
+	if v == "" {
+		return nil, nil
+	}
+
+	var attributes []aliasAttribute
+	for _, entry := range strings.Split(v, ",") {
+		key, prefix, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		if key == "" {
+			return nil, fmt.Errorf("TRACERY_ALIAS_ATTRIBUTES: empty attribute name in %q", v)
+		}
+		attributes = append(attributes, aliasAttribute{Key: key, Prefix: prefix})
+	}
+	log.Printf("[ControlPlane] Aliasing traces by span attributes: %s", v)
+	return attributes, nil
+}
+
+// aliasTraceFromAttributes registers an alias for every configured
+// attribute a span carries. Later spans win, as with RegisterTraceAlias.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) aliasTraceFromAttributes(traceID string, attrs map[string]string) {
+	for _, a := range s.aliasAttributes {
+		if v := attrs[a.Key]; v != "" {
+			s.traceAliases[a.Prefix+v] = traceID
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestAliasAttributesFromEnv(t *testing.T) {
+	t.Setenv("TRACERY_ALIAS_ATTRIBUTES", "order_id=order:, customer.id")
+	got, err := aliasAttributesFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []aliasAttribute{{Key: "order_id", Prefix: "order:"}, {Key: "customer.id"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("aliasAttributesFromEnv() = %v, want %v", got, want)
+	}
+
+	t.Setenv("TRACERY_ALIAS_ATTRIBUTES", "order_id,=x")
+	if _, err := aliasAttributesFromEnv(); err == nil {
+		t.Error("an empty attribute name was accepted")
+	}
+}
+
+func TestIngestRegistersAttributeAliases(t *testing.T) {
+	s := NewControlPlaneServer(nil, nil)
+	s.aliasAttributes = []aliasAttribute{{Key: "order.id", Prefix: "order:"}}
+
+	traces := ptrace.NewTraces()
+	span := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetTraceID([16]byte{1})
+	span.SetName("process")
+	span.Attributes().PutStr("order.id", "1234")
+	s.ingestTraces(traces)
+
+	want := span.TraceID().String()
+	if got := s.resolveTraceID("order:1234"); got != want {
+		t.Errorf("resolveTraceID(order:1234) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+)
+
+const (
+	// analyticsRetention is how long hit counts are kept.
+	analyticsRetention = 7 * 24 * time.Hour
+
+	// maxAnalyticsValues caps the attribute values tracked across all
+	// hours, so a high-cardinality attribute can't grow memory without
+	// bound. Values seen before the cap is reached keep counting.
+	maxAnalyticsValues = 50000
+
+	defaultAnalyticsHours     = 24
+	defaultAnalyticsTopValues = 10
+)
+
+type hitBucket struct {
+	Hour         int64
+	BreakpointID string
+	ServiceName  string
+	Endpoint     string
+}
+
+type valueBucket struct {
+	Hour         int64
+	BreakpointID string
+	Key          string
+	Value        string
+}
+
+// hitAnalytics aggregates breakpoint hits into hourly buckets. Counts
+// outlive the breakpoints they belong to, so a deleted breakpoint still
+// shows up in the history. It is guarded by ControlPlaneServer.mu.
+type hitAnalytics struct {
+	hits     map[hitBucket]int64
+	values   map[valueBucket]int64
+	names    map[string]string // breakpoint ID to its name at the last hit
+	prunedAt int64             // hour of the last prune
+}
+
+func newHitAnalytics() *hitAnalytics {
+	return &hitAnalytics{
+		hits:   make(map[hitBucket]int64),
+		values: make(map[valueBucket]int64),
+		names:  make(map[string]string),
+	}
+}
+
+func hourOf(t time.Time) int64 {
+	return t.Truncate(time.Hour).Unix()
+}
+
+// record counts one hit of bp by req.
+func (a *hitAnalytics) record(bp *BreakPoint, req *pb.CheckBreakpointRequest, now time.Time) {
+	hour := hourOf(now)
+	if hour != a.prunedAt {
+		a.prune(now.Add(-analyticsRetention))
+		a.prunedAt = hour
+	}
+
+	a.hits[hitBucket{Hour: hour, BreakpointID: bp.ID, ServiceName: req.GetServiceName(), Endpoint: req.GetEndpoint()}]++
+	if bp.Name != "" {
+		a.names[bp.ID] = bp.Name
+	}
+	for k, v := range req.GetAttributes() {
+		key := valueBucket{Hour: hour, BreakpointID: bp.ID, Key: k, Value: v}
+		if _, ok := a.values[key]; !ok && len(a.values) >= maxAnalyticsValues {
+			continue
+		}
+		a.values[key]++
+	}
+}
+
+// prune drops every bucket older than cutoff.
+func (a *hitAnalytics) prune(cutoff time.Time) {
+	oldest := hourOf(cutoff)
+	for key := range a.hits {
+		if key.Hour < oldest {
+			delete(a.hits, key)
+		}
+	}
+	for key := range a.values {
+		if key.Hour < oldest {
+			delete(a.values, key)
+		}
+	}
+}
+
+// GetBreakpointAnalytics reports hits per endpoint per hour and the
+// attribute values that most often matched, so teams can see which code
+// paths keep needing live debugging. Counts cover this replica since it
+// started, up to a week back.
+func (s *ControlPlaneServer) GetBreakpointAnalytics(ctx context.Context, req *pb.GetBreakpointAnalyticsRequest) (*pb.GetBreakpointAnalyticsResponse, error) {
+	hours := req.GetHours()
+	if hours <= 0 {
+		hours = defaultAnalyticsHours
+	}
+	if retained := int64(analyticsRetention / time.Hour); hours > retained {
+		hours = retained
+	}
+	top := int(req.GetTopValues())
+	if top <= 0 {
+		top = defaultAnalyticsTopValues
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Deleted breakpoints can still be asked about by ID or by the name
+	// they last had.
+	bpID := req.GetBreakpointId()
+	if bp := s.lookupBreakpoint(bpID); bp != nil {
+		bpID = bp.ID
+	} else if bpID != "" {
+		for id, name := range s.analytics.names {
+			if name == bpID {
+				bpID = id
+				break
+			}
+		}
+	}
+
+	since := hourOf(time.Now()) - (hours-1)*int64(time.Hour/time.Second)
+	resp := &pb.GetBreakpointAnalyticsResponse{Success: true}
+
+	for key, n := range s.analytics.hits {
+		if key.Hour < since || (bpID != "" && key.BreakpointID != bpID) {
+			continue
+		}
+		resp.TotalHits += n
+		resp.Hourly = append(resp.Hourly, &pb.HourlyHits{
+			Hour:           key.Hour,
+			BreakpointId:   key.BreakpointID,
+			BreakpointName: s.analytics.names[key.BreakpointID],
+			ServiceName:    key.ServiceName,
+			Endpoint:       key.Endpoint,
+			Hits:           n,
+		})
+	}
+	sort.Slice(resp.Hourly, func(i, j int) bool {
+		a, b := resp.Hourly[i], resp.Hourly[j]
+		if a.Hour != b.Hour {
+			return a.Hour < b.Hour
+		}
+		if a.ServiceName+a.Endpoint != b.ServiceName+b.Endpoint {
+			return a.ServiceName+a.Endpoint < b.ServiceName+b.Endpoint
+		}
+		return a.BreakpointId < b.BreakpointId
+	})
+
+	counts := make(map[[2]string]int64)
+	for key, n := range s.analytics.values {
+		if key.Hour < since || (bpID != "" && key.BreakpointID != bpID) {
+			continue
+		}
+		counts[[2]string{key.Key, key.Value}] += n
+	}
+	for kv, n := range counts {
+		resp.TopValues = append(resp.TopValues, &pb.AttributeValueCount{Key: kv[0], Value: kv[1], Hits: n})
+	}
+	sort.Slice(resp.TopValues, func(i, j int) bool {
+		a, b := resp.TopValues[i], resp.TopValues[j]
+		if a.Hits != b.Hits {
+			return a.Hits > b.Hits
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Value < b.Value
+	})
+	if len(resp.TopValues) > top {
+		resp.TopValues = resp.TopValues[:top]
+	}
+
+	resp.RespMessage = fmt.Sprintf("%d hit(s) in the last %d hour(s)", resp.TotalHits, hours)
+	return resp, nil
+}
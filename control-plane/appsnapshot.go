@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+)
+
+// maxSnapshotBytes bounds a single snapshot upload. Snapshots are captured
+// variables, not heap dumps; anything bigger is almost certainly a bug.
+const maxSnapshotBytes = 1 << 20
+
+// snapshotPayload is what the SDK posts to /app-snapshot. Only the routing
+// fields are interpreted here; the whole body is stored as the snapshot
+// data so new SDK fields show up without a control-plane change.
+type snapshotPayload struct {
+	TraceID      string `json:"trace_id"`
+	ServiceName  string `json:"service_name"`
+	Endpoint     string `json:"endpoint"`
+	BreakpointID string `json:"breakpoint_id"`
+	Checkpoint   string `json:"checkpoint"`
+}
+
+// handleAppSnapshot accepts snapshot uploads from SDKs that talk HTTP and
+// stores them the same way RecordSnapshot does.
+func (s *ControlPlaneServer) handleAppSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSnapshotBytes))
+	if err != nil {
+		http.Error(w, "snapshot too large or unreadable", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var payload snapshotPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid snapshot payload", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.RecordSnapshot(r.Context(), &pb.RecordSnapshotRequest{
+		TraceId:      payload.TraceID,
+		ServiceName:  payload.ServiceName,
+		Endpoint:     payload.Endpoint,
+		BreakpointId: payload.BreakpointID,
+		Checkpoint:   payload.Checkpoint,
+		Data:         string(body),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !resp.GetSuccess() {
+		http.Error(w, resp.GetRespMessage(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"snapshot_id": resp.GetSnapshotId(),
+	})
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAppSnapshotKeepsCheckpoint(t *testing.T) {
+	s := NewControlPlaneServer(NewMemorySnapshotStore(), nil)
+
+	body := `{"trace_id": "0102030405060708090a0b0c0d0e0f10", "service_name": "order-processing", "endpoint": "/orders", "checkpoint": "before-charge", "vars": {"amount": 1500}}`
+	rec := httptest.NewRecorder()
+	s.handleAppSnapshot(rec, httptest.NewRequest(http.MethodPost, "/app-snapshot", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	snaps, err := s.snapshots.List(SnapshotFilter{Checkpoint: "before-charge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 1 || snaps[0].Data != body {
+		t.Errorf("List(checkpoint) = %v, want the uploaded snapshot", snaps)
+	}
+}
@@ -0,0 +1,500 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// errBlobNotFound is returned by BlobStore.Get for a key that does not
+// exist.
+var errBlobNotFound = errors.New("blob not found")
+
+// BlobStore holds opaque payloads by key. Keys are slash-separated paths
+// made of ID characters.
+type BlobStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	// Delete removes a blob; a missing one is not an error.
+	Delete(key string) error
+	// Purge deletes blobs under prefix written before cutoff and returns
+	// how many were removed.
+	Purge(prefix string, cutoff time.Time) (int, error)
+}
+
+// newBlobStoreFromEnv opens the blob store named by
+// TRACERY_SNAPSHOT_BLOB_URL:
+//
+//	file:///data/blobs       local disk
+//	s3://bucket/prefix       S3 or an S3-compatible store
+//	gs://bucket/prefix       Google Cloud Storage
+//
+// S3 uses AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and
+// AWS_REGION; AWS_ENDPOINT_URL_S3 points it at MinIO and the like. GCS is
+// reached through its S3-compatible API with an HMAC key in
+// GCS_HMAC_ACCESS_ID and GCS_HMAC_SECRET. It returns nil when the variable
+// is unset.
+func newBlobStoreFromEnv() (BlobStore, error) {
+	raw := os.Getenv("TRACERY_SNAPSHOT_BLOB_URL")
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("TRACERY_SNAPSHOT_BLOB_URL: %w", err)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	switch u.Scheme {
+	case "file":
+		return NewLocalBlobStore(u.Path)
+	case "s3":
+		region := os.Getenv("AWS_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		endpoint := os.Getenv("AWS_ENDPOINT_URL_S3")
+		return &s3BlobStore{
+			endpoint:     endpoint,
+			pathStyle:    endpoint != "",
+			bucket:       u.Host,
+			prefix:       prefix,
+			region:       region,
+			accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			client:       &http.Client{Timeout: 30 * time.Second},
+		}, nil
+	case "gs":
+		return &s3BlobStore{
+			endpoint:  "https://storage.googleapis.com",
+			pathStyle: true,
+			bucket:    u.Host,
+			prefix:    prefix,
+			region:    "auto",
+			accessKey: os.Getenv("GCS_HMAC_ACCESS_ID"),
+			secretKey: os.Getenv("GCS_HMAC_SECRET"),
+			client:    &http.Client{Timeout: 30 * time.Second},
+		}, nil
+	}
+	return nil, fmt.Errorf("TRACERY_SNAPSHOT_BLOB_URL: unsupported scheme %q (want file, s3 or gs)", u.Scheme)
+}
+
+// localBlobStore keeps one file per blob under a directory.
+type localBlobStore struct {
+	dir string
+}
+
+func NewLocalBlobStore(dir string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &localBlobStore{dir: dir}, nil
+}
+
+func (l *localBlobStore) Put(key string, data []byte) error {
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (l *localBlobStore) Get(key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(l.dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errBlobNotFound
+	}
+	return data, err
+}
+
+func (l *localBlobStore) Delete(key string) error {
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	os.Remove(filepath.Dir(path)) // only succeeds once the directory is empty
+	return nil
+}
+
+func (l *localBlobStore) Purge(prefix string, cutoff time.Time) (int, error) {
+	root := filepath.Join(l.dir, filepath.FromSlash(prefix))
+	purged := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		purged++
+		os.Remove(filepath.Dir(path)) // only succeeds once the directory is empty
+		return nil
+	})
+	return purged, err
+}
+
+// s3BlobStore talks to the S3 REST API with SigV4-signed requests. Google
+// Cloud Storage and MinIO accept the same requests.
+type s3BlobStore struct {
+	endpoint     string // empty for AWS
+	pathStyle    bool
+	bucket       string
+	prefix       string
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+}
+
+func (s *s3BlobStore) objectURL(key string, query url.Values) string {
+	var base string
+	switch {
+	case s.pathStyle:
+		base = strings.TrimSuffix(s.endpoint, "/") + "/" + s.bucket + "/"
+	default:
+		base = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", s.bucket, s.region)
+	}
+	u := base + awsURIEncode(key, false)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (s *s3BlobStore) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.objectURL(key, query), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	signV4(req, body, s.accessKey, s.secretKey, s.region, "s3", time.Now())
+	return s.client.Do(req)
+}
+
+func (s *s3BlobStore) Put(key string, data []byte) error {
+	resp, err := s.do(http.MethodPut, s.prefix+key, nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s3Error(resp)
+}
+
+func (s *s3BlobStore) Get(key string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, s.prefix+key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errBlobNotFound
+	}
+	if err := s3Error(resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Delete succeeds for missing keys too, as S3 does.
+func (s *s3BlobStore) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, s.prefix+key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s3Error(resp)
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// Purge lists the objects under prefix and deletes the old ones one by one.
+// Buckets with a lifecycle rule for the prefix do not need it.
+func (s *s3BlobStore) Purge(prefix string, cutoff time.Time) (int, error) {
+	purged := 0
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.prefix + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return purged, err
+		}
+		var list s3ListResult
+		if err := s3Error(resp); err != nil {
+			resp.Body.Close()
+			return purged, err
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return purged, fmt.Errorf("listing %s: %w", s.bucket, err)
+		}
+
+		for _, obj := range list.Contents {
+			if !obj.LastModified.Before(cutoff) {
+				continue
+			}
+			resp, err := s.do(http.MethodDelete, obj.Key, nil, nil)
+			if err != nil {
+				return purged, err
+			}
+			resp.Body.Close()
+			if err := s3Error(resp); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+		if !list.IsTruncated {
+			return purged, nil
+		}
+		token = list.NextContinuationToken
+	}
+}
+
+func s3Error(resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s %s: %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, bytes.TrimSpace(body))
+}
+
+// signV4 signs req with AWS Signature Version 4, covering the host and every
+// header already set on it.
+func signV4(req *http.Request, body []byte, accessKey, secretKey, region, service string, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsURIEncode percent-encodes everything but unreserved characters, and
+// slashes too when encodeSlash is set, as SigV4 requires.
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// blobSnapshotStore keeps snapshot metadata in another SnapshotStore and the
+// captured data, which can be large, in a BlobStore.
+type blobSnapshotStore struct {
+	meta  SnapshotStore
+	blobs BlobStore
+}
+
+// snapshotBlobPrefix is where snapshot payloads live in the blob store.
+const snapshotBlobPrefix = "snapshots/"
+
+func NewBlobSnapshotStore(meta SnapshotStore, blobs BlobStore) SnapshotStore {
+	return &blobSnapshotStore{meta: meta, blobs: blobs}
+}
+
+func (b *blobSnapshotStore) Save(snap *Snapshot) error {
+	key := snapshotBlobPrefix + snap.TraceID + "/" + snap.ID + ".json"
+	if err := b.blobs.Put(key, []byte(snap.Data)); err != nil {
+		return fmt.Errorf("storing snapshot data: %w", err)
+	}
+	meta := *snap
+	meta.Data = ""
+	meta.BlobKey = key
+	return b.meta.Save(&meta)
+}
+
+func (b *blobSnapshotStore) Get(traceID, serviceName string) ([]*Snapshot, error) {
+	snaps, err := b.meta.Get(traceID, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return b.withData(snaps)
+}
+
+func (b *blobSnapshotStore) List(filter SnapshotFilter) ([]*Snapshot, error) {
+	snaps, err := b.meta.List(filter)
+	if err != nil || filter.MetadataOnly {
+		return snaps, err
+	}
+	return b.withData(snaps)
+}
+
+// withData returns copies of snaps with their data loaded from the blob
+// store. Snapshots whose data has gone missing are left out.
+func (b *blobSnapshotStore) withData(snaps []*Snapshot) ([]*Snapshot, error) {
+	out := make([]*Snapshot, 0, len(snaps))
+	for _, snap := range snaps {
+		if snap.BlobKey == "" {
+			// Stored before the blob store was configured.
+			out = append(out, snap)
+			continue
+		}
+		data, err := b.blobs.Get(snap.BlobKey)
+		if errors.Is(err, errBlobNotFound) {
+			log.Printf("[ControlPlane] Skipping snapshot %s: its data is missing from the blob store", snap.ID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		full := *snap
+		full.Data = string(data)
+		full.BlobKey = ""
+		out = append(out, &full)
+	}
+	return out, nil
+}
+
+// Delete removes metadata first, for the same reason Purge does.
+func (b *blobSnapshotStore) Delete(traceID, id string) (bool, error) {
+	snaps, err := b.meta.Get(traceID, "")
+	if err != nil {
+		return false, err
+	}
+	for _, snap := range snaps {
+		if snap.ID != id {
+			continue
+		}
+		if _, err := b.meta.Delete(traceID, id); err != nil {
+			return false, err
+		}
+		if snap.BlobKey != "" {
+			if err := b.blobs.Delete(snap.BlobKey); err != nil {
+				return true, fmt.Errorf("deleting snapshot data: %w", err)
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// Purge removes metadata first, so a failure part way leaves orphaned
+// payloads rather than snapshots without data.
+func (b *blobSnapshotStore) Purge(cutoff time.Time) (int, error) {
+	purged, err := b.meta.Purge(cutoff)
+	if err != nil {
+		return purged, err
+	}
+	if _, err := b.blobs.Purge(snapshotBlobPrefix, cutoff); err != nil {
+		return purged, fmt.Errorf("purging snapshot data: %w", err)
+	}
+	return purged, nil
+}
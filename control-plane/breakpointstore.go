@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	_ "github.com/lib/pq"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// breakpointSyncInterval is how often a replica reloads breakpoints from a
+// shared store to pick up changes made through other replicas.
+const breakpointSyncInterval = 5 * time.Second
+
+// breakpointStoreTimeout bounds every store query. Most writes happen under
+// s.mu, so a hung database would otherwise stall span ingest and every RPC.
+const breakpointStoreTimeout = 2 * time.Second
+
+// BreakpointStore persists breakpoints so they survive control-plane
+// restarts. The in-memory map stays the working copy; every change is
+// written through to the store.
+type BreakpointStore interface {
+	Save(bp *BreakPoint) error
+	Delete(id string) error
+	List() ([]*BreakPoint, error)
+	// AddHits atomically adds n to a breakpoint's hit count and returns
+	// the new total across every replica, or errBreakpointNotFound.
+	AddHits(id string, n int64) (int64, error)
+}
+
+var errBreakpointNotFound = errors.New("breakpoint not found")
+
+// newBreakpointStoreFromEnv connects to the Postgres database named by
+// TRACERY_POSTGRES_DSN. It returns a nil store when it is unset, in which
+// case breakpoints are kept in memory only.
+func newBreakpointStoreFromEnv() (BreakpointStore, error) {
+	dsn := os.Getenv("TRACERY_POSTGRES_DSN")
+	if dsn == "" {
+		log.Printf("[ControlPlane] TRACERY_POSTGRES_DSN not set, breakpoints are kept in memory only")
+		return nil, nil
+	}
+	return NewPostgresBreakpointStore(dsn)
+}
+
+// postgresBreakpointStore keeps one row per breakpoint. Several replicas can
+// share it; each reloads the table every breakpointSyncInterval.
+type postgresBreakpointStore struct {
+	db *sql.DB
+}
+
+func NewPostgresBreakpointStore(dsn string) (BreakpointStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Postgres usually comes up alongside the control plane, so give it a
+	// moment before giving up.
+	for i := 0; i < 10; i++ {
+		if err = db.Ping(); err == nil {
+			break
+		}
+		log.Printf("[ControlPlane] Waiting for breakpoint database... (attempt %d/10)", i+1)
+		time.Sleep(2 * time.Second)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS tracery_breakpoints (
+			id                    TEXT PRIMARY KEY,
+			name                  TEXT NOT NULL DEFAULT '',
+			service_name          TEXT NOT NULL DEFAULT '',
+			endpoint              TEXT NOT NULL DEFAULT '',
+			conditions            JSONB NOT NULL DEFAULT '{}',
+			expression            TEXT NOT NULL DEFAULT '',
+			enabled               BOOLEAN NOT NULL,
+			created_at            TIMESTAMPTZ NOT NULL,
+			expires_at            TIMESTAMPTZ,
+			hit_count             BIGINT NOT NULL DEFAULT 0,
+			max_hits              BIGINT NOT NULL DEFAULT 0,
+			delete_after_max_hits BOOLEAN NOT NULL DEFAULT FALSE,
+			keep_after_expiry     BOOLEAN NOT NULL DEFAULT FALSE,
+			single_span           BOOLEAN NOT NULL DEFAULT FALSE,
+			source                JSONB
+		)
+	`)
+	if err == nil {
+		// Tables created before these columns existed.
+		_, err = db.Exec(`
+			ALTER TABLE tracery_breakpoints
+				ADD COLUMN IF NOT EXISTS single_span BOOLEAN NOT NULL DEFAULT FALSE,
+				ADD COLUMN IF NOT EXISTS source JSONB
+		`)
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create breakpoints table: %w", err)
+	}
+	return &postgresBreakpointStore{db: db}, nil
+}
+
+func (p *postgresBreakpointStore) Save(bp *BreakPoint) error {
+	conditions, err := json.Marshal(bp.Conditions)
+	if err != nil {
+		return err
+	}
+	var expiresAt sql.NullTime
+	if !bp.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: bp.ExpiresAt, Valid: true}
+	}
+	var source sql.NullString
+	if bp.Source != nil {
+		data, err := protojson.Marshal(bp.Source)
+		if err != nil {
+			return err
+		}
+		source = sql.NullString{String: string(data), Valid: true}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), breakpointStoreTimeout)
+	defer cancel()
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO tracery_breakpoints (id, name, service_name, endpoint, conditions, expression,
+			enabled, created_at, expires_at, hit_count, max_hits, delete_after_max_hits, keep_after_expiry,
+			single_span, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			service_name = EXCLUDED.service_name,
+			endpoint = EXCLUDED.endpoint,
+			conditions = EXCLUDED.conditions,
+			expression = EXCLUDED.expression,
+			enabled = EXCLUDED.enabled,
+			expires_at = EXCLUDED.expires_at,
+			-- AddHits owns the count; a stale copy must not lower it.
+			hit_count = GREATEST(tracery_breakpoints.hit_count, EXCLUDED.hit_count),
+			max_hits = EXCLUDED.max_hits,
+			delete_after_max_hits = EXCLUDED.delete_after_max_hits,
+			keep_after_expiry = EXCLUDED.keep_after_expiry,
+			single_span = EXCLUDED.single_span,
+			source = EXCLUDED.source
+	`, bp.ID, bp.Name, bp.ServiceName, bp.EndPoint, string(conditions), bp.Expression,
+		bp.Enabled, bp.CreatedAt, expiresAt, bp.HitCount, bp.MaxHits, bp.DeleteAfterMaxHits, bp.KeepAfterExpiry,
+		bp.SingleSpan, source)
+	return err
+}
+
+func (p *postgresBreakpointStore) AddHits(id string, n int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), breakpointStoreTimeout)
+	defer cancel()
+	var total int64
+	err := p.db.QueryRowContext(ctx, `
+		UPDATE tracery_breakpoints SET hit_count = hit_count + $2 WHERE id = $1 RETURNING hit_count
+	`, id, n).Scan(&total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errBreakpointNotFound
+	}
+	return total, err
+}
+
+func (p *postgresBreakpointStore) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), breakpointStoreTimeout)
+	defer cancel()
+	_, err := p.db.ExecContext(ctx, `DELETE FROM tracery_breakpoints WHERE id = $1`, id)
+	return err
+}
+
+func (p *postgresBreakpointStore) List() ([]*BreakPoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), breakpointStoreTimeout)
+	defer cancel()
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, name, service_name, endpoint, conditions, expression, enabled, created_at,
+			expires_at, hit_count, max_hits, delete_after_max_hits, keep_after_expiry, single_span,
+			source
+		FROM tracery_breakpoints
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var breakpoints []*BreakPoint
+	for rows.Next() {
+		var (
+			bp         BreakPoint
+			conditions []byte
+			expiresAt  sql.NullTime
+			source     []byte
+		)
+		err := rows.Scan(&bp.ID, &bp.Name, &bp.ServiceName, &bp.EndPoint, &conditions, &bp.Expression,
+			&bp.Enabled, &bp.CreatedAt, &expiresAt, &bp.HitCount, &bp.MaxHits, &bp.DeleteAfterMaxHits, &bp.KeepAfterExpiry,
+			&bp.SingleSpan, &source)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(conditions, &bp.Conditions); err != nil {
+			return nil, fmt.Errorf("breakpoint %s: %w", bp.ID, err)
+		}
+		if expiresAt.Valid {
+			bp.ExpiresAt = expiresAt.Time
+		}
+		if source != nil {
+			bp.Source = &pb.SourceLocation{}
+			if err := protojson.Unmarshal(source, bp.Source); err != nil {
+				return nil, fmt.Errorf("breakpoint %s source: %w", bp.ID, err)
+			}
+		}
+		breakpoints = append(breakpoints, &bp)
+	}
+	return breakpoints, rows.Err()
+}
+
+// persistBreakpoint writes bp through to the breakpoint store, if there is
+// one. Callers must hold s.mu.
+func (s *ControlPlaneServer) persistBreakpoint(bp *BreakPoint) error {
+	if s.breakpointStore == nil {
+		return nil
+	}
+	s.breakpointWrites++
+	return s.breakpointStore.Save(bp)
+}
+
+// unpersistBreakpoint removes bp from the breakpoint store, if there is one.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) unpersistBreakpoint(bp *BreakPoint) error {
+	if s.breakpointStore == nil {
+		return nil
+	}
+	s.breakpointWrites++
+	return s.breakpointStore.Delete(bp.ID)
+}
+
+// loadBreakpoints merges the contents of the breakpoint store into the
+// in-memory breakpoints. Breakpoints whose expression no longer compiles are
+// skipped rather than matching everything.
+func (s *ControlPlaneServer) loadBreakpoints() error {
+	if s.breakpointStore == nil {
+		return nil
+	}
+	s.mu.RLock()
+	writes := s.breakpointWrites
+	s.mu.RUnlock()
+
+	stored, err := s.breakpointStore.List()
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]*BreakPoint, len(stored))
+	for _, bp := range stored {
+		if bp.Expression != "" {
+			bp.expr, err = compileCondition(bp.Expression)
+			if err != nil {
+				log.Printf("[ControlPlane] Skipping stored breakpoint %s: invalid expression: %v", bp.ID, err)
+				continue
+			}
+		}
+		loaded[bp.ID] = bp
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A write made while List ran may be missing from its result; applying
+	// it would undo the write until the next sync, so wait for that one.
+	if s.breakpointWrites != writes {
+		return nil
+	}
+
+	for id := range s.breakPoints {
+		if _, ok := loaded[id]; !ok {
+			delete(s.breakPoints, id)
+		}
+	}
+	for id, bp := range loaded {
+		// Breakpoints stored before endpoint normalization may name
+		// concrete paths that spans no longer carry.
+		bp.EndPoint = s.normalizeEndpoint(bp.EndPoint)
+		// Hits runHitWriter hasn't written yet still count.
+		bp.HitCount += s.hits.pending(id)
+
+		// Update in place so pointers held elsewhere stay current.
+		if live, ok := s.breakPoints[id]; ok {
+			*live = *bp
+			continue
+		}
+		s.breakPoints[id] = bp
+	}
+	return nil
+}
+
+// hitWriteInterval is how often hits are written to the breakpoint store.
+const hitWriteInterval = 200 * time.Millisecond
+
+// pendingHits collects hits until runHitWriter writes them, so recording a
+// hit never waits on the database. It has its own lock so the writer can
+// take the batch without s.mu.
+type pendingHits struct {
+	mu   sync.Mutex
+	byID map[string]int64
+}
+
+func (h *pendingHits) add(id string) {
+	h.addN(id, 1)
+}
+
+func (h *pendingHits) addN(id string, n int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.byID == nil {
+		h.byID = make(map[string]int64)
+	}
+	h.byID[id] += n
+}
+
+func (h *pendingHits) pending(id string) int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.byID[id]
+}
+
+func (h *pendingHits) take() map[string]int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	batch := h.byID
+	h.byID = nil
+	return batch
+}
+
+// runHitWriter adds pending hits to the store's counts, outside s.mu, and
+// retires breakpoints whose shared count reached max_hits. The store's
+// count covers every replica, so max_hits holds across all of them.
+func (s *ControlPlaneServer) runHitWriter(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.writeHits()
+	}
+}
+
+func (s *ControlPlaneServer) writeHits() {
+	for id, n := range s.hits.take() {
+		total, err := s.breakpointStore.AddHits(id, n)
+		if errors.Is(err, errBreakpointNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("[ControlPlane] Failed to record %d hit(s) for breakpoint %s: %v", n, id, err)
+			s.hits.addN(id, n)
+			continue
+		}
+
+		s.mu.Lock()
+		if bp, ok := s.breakPoints[id]; ok {
+			if total > bp.HitCount {
+				bp.HitCount = total
+			}
+			s.retireIfSpent(bp)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// runBreakpointSync periodically reloads breakpoints so every replica
+// sharing a store sees the same set.
+func (s *ControlPlaneServer) runBreakpointSync(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := s.loadBreakpoints()
+		if err != nil {
+			log.Printf("[ControlPlane] Failed to reload breakpoints: %v", err)
+		}
+		s.mu.Lock()
+		s.breakpointSyncErr = err
+		s.mu.Unlock()
+	}
+}
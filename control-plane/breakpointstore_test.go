@@ -0,0 +1,96 @@
+package main
+
+import "testing"
+
+// fakeBreakpointStore keeps rows in a map. onList, if set, runs while List
+// is in progress.
+type fakeBreakpointStore struct {
+	rows   map[string]BreakPoint
+	onList func()
+}
+
+func (f *fakeBreakpointStore) Save(bp *BreakPoint) error {
+	f.rows[bp.ID] = *bp
+	return nil
+}
+
+func (f *fakeBreakpointStore) Delete(id string) error {
+	delete(f.rows, id)
+	return nil
+}
+
+func (f *fakeBreakpointStore) List() ([]*BreakPoint, error) {
+	var list []*BreakPoint
+	for _, row := range f.rows {
+		bp := row
+		list = append(list, &bp)
+	}
+	if f.onList != nil {
+		f.onList()
+	}
+	return list, nil
+}
+
+func (f *fakeBreakpointStore) AddHits(id string, n int64) (int64, error) {
+	row, ok := f.rows[id]
+	if !ok {
+		return 0, errBreakpointNotFound
+	}
+	row.HitCount += n
+	f.rows[id] = row
+	return row.HitCount, nil
+}
+
+func TestLoadBreakpointsMergesIntoLiveState(t *testing.T) {
+	store := &fakeBreakpointStore{rows: map[string]BreakPoint{
+		"kept": {ID: "kept", ServiceName: "svc", EndPoint: "/orders/42", Enabled: true, HitCount: 5},
+		"gone": {ID: "gone", ServiceName: "svc", Enabled: true},
+	}}
+	s := NewControlPlaneServer(nil, store)
+	if err := s.loadBreakpoints(); err != nil {
+		t.Fatal(err)
+	}
+	live := s.breakPoints["kept"]
+	if live == nil || live.EndPoint != "/orders/{id}" {
+		t.Fatalf("kept = %+v, want its endpoint normalized", live)
+	}
+
+	// Another replica deletes one breakpoint and records a hit on the
+	// other, while this one has a hit not yet written.
+	delete(store.rows, "gone")
+	store.AddHits("kept", 1)
+	s.hits.add("kept")
+	if err := s.loadBreakpoints(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.breakPoints["gone"]; ok {
+		t.Error("a breakpoint deleted from the store survived the reload")
+	}
+	if s.breakPoints["kept"] != live {
+		t.Error("the reload replaced the live breakpoint instead of updating it")
+	}
+	if live.HitCount != 7 {
+		t.Errorf("HitCount = %d, want 7: 6 stored plus 1 pending", live.HitCount)
+	}
+}
+
+func TestLoadBreakpointsSkipsReloadThatRacedAWrite(t *testing.T) {
+	store := &fakeBreakpointStore{rows: map[string]BreakPoint{}}
+	s := NewControlPlaneServer(nil, store)
+
+	// A breakpoint registered while List runs is missing from its result.
+	bp := &BreakPoint{ID: "new", ServiceName: "svc", Enabled: true}
+	store.onList = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.persistBreakpoint(bp)
+		s.breakPoints[bp.ID] = bp
+	}
+	if err := s.loadBreakpoints(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.breakPoints["new"]; !ok {
+		t.Error("a reload that raced a registration deleted the new breakpoint")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+)
+
+const (
+	bulkDelete  = "delete"
+	bulkEnable  = "enable"
+	bulkDisable = "disable"
+)
+
+// BulkUpdateBreakpoints deletes, enables or disables every breakpoint a
+// filter matches in one call. A dry run returns the matches so a client can
+// confirm them and then pass their IDs back, so that breakpoints created in
+// between are left alone.
+func (s *ControlPlaneServer) BulkUpdateBreakpoints(ctx context.Context, req *pb.BulkUpdateBreakpointsRequest) (*pb.BulkUpdateBreakpointsResponse, error) {
+	action := req.GetAction()
+	if action != bulkDelete && action != bulkEnable && action != bulkDisable {
+		return &pb.BulkUpdateBreakpointsResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("unknown action %q: use delete, enable or disable", action),
+		}, nil
+	}
+	filter := req.GetFilter()
+	if err := validateBreakpointFilter(filter); err != nil {
+		return &pb.BulkUpdateBreakpointsResponse{
+			Success:     false,
+			RespMessage: err.Error(),
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var only map[string]bool
+	if len(req.GetBreakpointIds()) > 0 {
+		only = make(map[string]bool, len(req.GetBreakpointIds()))
+		for _, id := range req.GetBreakpointIds() {
+			only[id] = true
+		}
+	}
+
+	now := time.Now()
+	var matched []*BreakPoint
+	for _, bp := range s.breakPoints {
+		// Enabling or disabling skips breakpoints already in that state.
+		if !matchesBreakpointFilter(bp, filter, now) ||
+			(action == bulkEnable && bp.Enabled) || (action == bulkDisable && !bp.Enabled) ||
+			(only != nil && !only[bp.ID]) {
+			continue
+		}
+		matched = append(matched, bp)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+	})
+
+	resp := &pb.BulkUpdateBreakpointsResponse{Success: true}
+	for _, bp := range matched {
+		resp.Matched = append(resp.Matched, bp.proto())
+	}
+	if req.GetDryRun() {
+		resp.RespMessage = fmt.Sprintf("%d breakpoint(s) would be %s", len(matched), bulkPastTense(action))
+		return resp, nil
+	}
+
+	for _, bp := range matched {
+		var err error
+		switch action {
+		case bulkDelete:
+			err = s.removeBreakpoint(bp)
+		case bulkEnable:
+			err = s.setBreakpointEnabled(bp, true)
+		case bulkDisable:
+			err = s.setBreakpointEnabled(bp, false)
+		}
+		if err != nil {
+			resp.Failed = append(resp.Failed, bp.ID)
+			continue
+		}
+		resp.Applied++
+	}
+
+	resp.RespMessage = fmt.Sprintf("%d breakpoint(s) %s", resp.Applied, bulkPastTense(action))
+	if len(resp.Failed) > 0 {
+		resp.Success = false
+		resp.RespMessage += fmt.Sprintf(", %d failed: %s", len(resp.Failed), strings.Join(resp.Failed, ", "))
+	}
+	return resp, nil
+}
+
+// validateBreakpointFilter insists on at least one criterion, so a bulk
+// delete never takes out every breakpoint by accident.
+func validateBreakpointFilter(filter *pb.BreakpointFilter) error {
+	if filter.GetServiceName() == "" && filter.GetEndpoint() == "" && filter.GetState() == "" && filter.GetOlderThanSeconds() == 0 {
+		return fmt.Errorf("at least one filter is required")
+	}
+	if state := filter.GetState(); state != "" && state != "enabled" && state != "disabled" {
+		return fmt.Errorf("state must be enabled or disabled, not %q", state)
+	}
+	if filter.GetOlderThanSeconds() < 0 {
+		return fmt.Errorf("older_than_seconds must not be negative")
+	}
+	return nil
+}
+
+func matchesBreakpointFilter(bp *BreakPoint, filter *pb.BreakpointFilter, now time.Time) bool {
+	switch {
+	case filter.GetServiceName() != "" && bp.ServiceName != filter.GetServiceName(),
+		filter.GetEndpoint() != "" && bp.EndPoint != filter.GetEndpoint(),
+		filter.GetState() == "enabled" && !bp.Enabled,
+		filter.GetState() == "disabled" && bp.Enabled,
+		filter.GetOlderThanSeconds() > 0 && now.Sub(bp.CreatedAt) < time.Duration(filter.GetOlderThanSeconds())*time.Second:
+		return false
+	}
+	return true
+}
+
+func bulkPastTense(action string) string {
+	if action == bulkDelete {
+		return "deleted"
+	}
+	return action + "d"
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// CheckBreakpoint is called by SDKs for each request to learn whether any
+// enabled breakpoint matches it. Every match is published as a
+// breakpoint_hit event and counts towards the breakpoint's max_hits.
+func (s *ControlPlaneServer) CheckBreakpoint(ctx context.Context, req *pb.CheckBreakpointRequest) (*pb.CheckBreakpointResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoint := s.normalizeEndpoint(req.GetEndpoint())
+	if endpoint != req.GetEndpoint() {
+		req = proto.Clone(req).(*pb.CheckBreakpointRequest)
+		req.RawEndpoint = req.Endpoint
+		req.Endpoint = endpoint
+	}
+
+	hits := s.evaluateBreakpoints(req)
+	return &pb.CheckBreakpointResponse{
+		Hit:           len(hits) > 0,
+		BreakpointIds: hits,
+		Endpoint:      endpoint,
+	}, nil
+}
+
+// evaluateBreakpoints publishes a hit for every breakpoint matching req and
+// returns their IDs. Breakpoints that are not single-span also match when
+// the attributes of all the service's spans in the trace do together, at
+// most once per trace. Callers must hold s.mu.
+func (s *ControlPlaneServer) evaluateBreakpoints(req *pb.CheckBreakpointRequest) []string {
+	var (
+		hits       []string
+		aggregated *matchTarget
+	)
+	now := time.Now()
+	single := &matchTarget{
+		ServiceName: req.GetServiceName(),
+		Endpoints:   []string{req.GetEndpoint()},
+		Attributes:  req.GetAttributes(),
+	}
+	partial := *single
+	partial.Partial = true
+
+	for _, bp := range s.breakPoints {
+		target := &partial
+		if bp.SingleSpan {
+			target = single
+		}
+		if !s.breakpointMatches(bp, req.GetTraceId(), target) {
+			if bp.SingleSpan || !bp.Enabled || bp.spent() || bp.ServiceName != req.GetServiceName() {
+				continue
+			}
+			if aggregated == nil {
+				aggregated = s.traces.serviceTarget(req)
+			}
+			if s.traces.hit(req.GetTraceId(), bp.ID) || !s.breakpointMatches(bp, req.GetTraceId(), aggregated) {
+				continue
+			}
+			target = aggregated
+		}
+		s.traces.markHit(req.GetTraceId(), bp.ID)
+
+		hits = append(hits, bp.ID)
+		s.broadcast(&pb.TraceEvent{
+			TraceId:      req.GetTraceId(),
+			ServiceName:  req.GetServiceName(),
+			Endpoint:     req.GetEndpoint(),
+			RawEndpoint:  req.GetRawEndpoint(),
+			Timestamp:    now.Unix(),
+			Attributes:   target.Attributes,
+			EventType:    eventTypeBreakpointHit,
+			BreakpointId: bp.ID,
+		})
+		s.analytics.record(bp, req, now)
+		s.recordHit(bp)
+	}
+	return hits
+}
+
+// matchTarget is what a breakpoint is evaluated against: one span, or the
+// union of a service's spans within a trace.
+type matchTarget struct {
+	ServiceName string
+	Endpoints   []string
+	Attributes  map[string]string
+
+	// Partial targets are one span of a trace whose other spans may still
+	// supply missing attributes, so expression errors are not logged.
+	Partial bool
+}
+
+// breakpointMatches applies a breakpoint's location, exact conditions and
+// expression to a target. An empty breakpoint endpoint matches every
+// endpoint of the service. Callers must hold s.mu.
+func (s *ControlPlaneServer) breakpointMatches(bp *BreakPoint, traceID string, target *matchTarget) bool {
+	if !bp.Enabled || bp.spent() || bp.ServiceName != target.ServiceName {
+		return false
+	}
+	if bp.EndPoint != "" {
+		found := false
+		for _, endpoint := range target.Endpoints {
+			if endpoint == bp.EndPoint {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for k, v := range bp.Conditions {
+		if target.Attributes[k] != v {
+			return false
+		}
+	}
+	if bp.expr == nil {
+		return true
+	}
+
+	ok, err := bp.expr.Matches(target.Attributes)
+	if err != nil {
+		if target.Partial {
+			return false
+		}
+		log.Printf("[ControlPlane] Breakpoint %s expression did not evaluate for trace %s: %v", bp.ID, traceID, err)
+		return false
+	}
+	return ok
+}
+
+// recordHit counts a hit. With a breakpoint store the hit is persisted, and
+// the breakpoint retired, by runHitWriter, so span ingestion never waits on
+// the database. Callers must hold s.mu.
+func (s *ControlPlaneServer) recordHit(bp *BreakPoint) {
+	bp.HitCount++
+	if s.breakpointStore != nil {
+		s.hits.add(bp.ID)
+		return
+	}
+	s.retireIfSpent(bp)
+}
+
+// spent reports whether a breakpoint has used up its max_hits. Spent
+// breakpoints stop matching right away, before they are retired.
+func (bp *BreakPoint) spent() bool {
+	return bp.MaxHits > 0 && bp.HitCount >= bp.MaxHits
+}
+
+// retireIfSpent deletes or disables a breakpoint that has used up its
+// max_hits, so a one-shot breakpoint can't keep firing in a shared
+// environment. Callers must hold s.mu.
+func (s *ControlPlaneServer) retireIfSpent(bp *BreakPoint) {
+	if !bp.Enabled || !bp.spent() {
+		return
+	}
+
+	if bp.DeleteAfterMaxHits {
+		if err := s.removeBreakpoint(bp); err == nil {
+			log.Printf("[ControlPlane] Breakpoint %s deleted after %d hit(s)", bp.ID, bp.HitCount)
+		}
+		return
+	}
+
+	updated := *bp
+	updated.Enabled = false
+	if err := s.persistBreakpoint(&updated); err != nil {
+		log.Printf("[ControlPlane] Failed to persist breakpoint %s: %v", bp.ID, err)
+		return
+	}
+	*bp = updated
+	log.Printf("[ControlPlane] Breakpoint %s disabled after %d hit(s)", bp.ID, bp.HitCount)
+	s.broadcast(breakpointEvent(eventTypeBreakpointDisabled, bp))
+}
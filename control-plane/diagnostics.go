@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"sync"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxRecentErrors = 100
+	redacted        = "[redacted]"
+)
+
+type recentError struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	RequestID string    `json:"request_id,omitempty"`
+	Caller    string    `json:"caller,omitempty"`
+	Code      string    `json:"code"`
+	Message   string    `json:"message"`
+}
+
+// ErrorLog keeps the most recent RPC failures for support bundles.
+type ErrorLog struct {
+	mu      sync.Mutex
+	entries []recentError
+}
+
+func NewErrorLog() *ErrorLog {
+	return &ErrorLog{entries: make([]recentError, 0, maxRecentErrors)}
+}
+
+func (e *ErrorLog) record(ctx context.Context, method string, err error) {
+	if err == nil {
+		return
+	}
+	st := status.Convert(err)
+	info := callerInfo(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.entries) == maxRecentErrors {
+		e.entries = e.entries[1:]
+	}
+	e.entries = append(e.entries, recentError{
+		Time:      time.Now(),
+		Method:    method,
+		RequestID: info.ID,
+		Caller:    info.String(),
+		Code:      st.Code().String(),
+		Message:   st.Message(),
+	})
+}
+
+func (e *ErrorLog) snapshot() []recentError {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]recentError(nil), e.entries...)
+}
+
+// countSince returns how many errors were recorded after t.
+func (e *ErrorLog) countSince(t time.Time) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := 0
+	for i := len(e.entries) - 1; i >= 0 && e.entries[i].Time.After(t); i-- {
+		n++
+	}
+	return n
+}
+
+func (e *ErrorLog) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	e.record(ctx, info.FullMethod, err)
+	return resp, err
+}
+
+func (e *ErrorLog) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	e.record(ss.Context(), info.FullMethod, err)
+	return err
+}
+
+// GetSupportBundle dumps control-plane state for bug reports. Condition and
+// alias values can carry customer data, so only their keys are included.
+func (s *ControlPlaneServer) GetSupportBundle(ctx context.Context, req *pb.GetSupportBundleRequest) (*pb.GetSupportBundleResponse, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	breakpoints := make([]map[string]interface{}, 0, len(s.breakPoints))
+	for _, bp := range s.breakPoints {
+		breakpoints = append(breakpoints, map[string]interface{}{
+			"id":          bp.ID,
+			"name":        bp.Name,
+			"service":     bp.ServiceName,
+			"endpoint":    bp.EndPoint,
+			"conditions":  redactValues(bp.Conditions),
+			"expression":  redactExpression(bp.Expression),
+			"enabled":     bp.Enabled,
+			"hit_count":   bp.HitCount,
+			"max_hits":    bp.MaxHits,
+			"single_span": bp.SingleSpan,
+			"source":      sourceString(bp.Source),
+			"expires_at":  bp.ExpiresAt,
+			"created_at":  bp.CreatedAt,
+		})
+	}
+	sampling := make([]map[string]interface{}, 0, len(s.samplingRules))
+	for _, rule := range s.samplingRules {
+		sampling = append(sampling, map[string]interface{}{
+			"id":               rule.ID,
+			"service":          rule.ServiceName,
+			"endpoint":         rule.EndPoint,
+			"rate":             rule.Rate,
+			"force_conditions": redactValues(rule.ForceConditions),
+		})
+	}
+	subscriberClasses := make(map[string]interface{}, len(s.subscriberClasses))
+	for name, class := range s.subscriberClasses {
+		subscriberClasses[name] = map[string]interface{}{
+			"buffer_size": class.BufferSize,
+			"drop_policy": class.DropPolicy,
+			"dropped":     class.Dropped.Load(),
+		}
+	}
+	rewrites := make([]map[string]string, 0, len(s.endpointRewrites))
+	for _, r := range s.endpointRewrites {
+		rewrites = append(rewrites, map[string]string{"pattern": r.Pattern, "replacement": r.Replacement})
+	}
+	state := map[string]interface{}{
+		"breakpoints":      len(s.breakPoints),
+		"trace_listeners":  len(s.traceListeners),
+		"trace_aliases":    len(s.traceAliases),
+		"stored_traces":    len(s.traces.traces),
+		"sampling_rules":   len(s.samplingRules),
+		"sampling_version": s.samplingVersion,
+		"leader":           s.isLeader(),
+		"failing_checks":   s.failingChecks,
+		"forwarding":       s.forwarder.stats(),
+		"log_export":       s.logExporter.stats(),
+		"storage":          s.storageStats(),
+	}
+	s.mu.RUnlock()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	sections := map[string]interface{}{
+		"version.json": map[string]interface{}{
+			"go_version": runtime.Version(),
+			"started_at": s.startedAt,
+			"uptime":     now.Sub(s.startedAt).String(),
+		},
+		"config.json": map[string]interface{}{
+			"default_unary_limit":    defaultUnaryLimit,
+			"method_limits":          methodLimits,
+			"max_streams_per_client": maxStreamsPerClient,
+			"max_streams_total":      maxStreamsTotal,
+			"default_sampling_rate":  defaultSamplingRate,
+			"trace_idle_timeout":     defaultTraceIdleTimeout.String(),
+			"subscriber_classes":     subscriberClasses,
+			"endpoint_rewrites":      rewrites,
+		},
+		"state.json":         state,
+		"breakpoints.json":   breakpoints,
+		"sampling.json":      sampling,
+		"recent_errors.json": s.errors.snapshot(),
+		"rpc_stats.json":     s.requests.snapshot(),
+		"metrics.json": map[string]interface{}{
+			"goroutines":        runtime.NumGoroutine(),
+			"heap_alloc_bytes":  mem.HeapAlloc,
+			"heap_objects":      mem.HeapObjects,
+			"gc_cycles":         mem.NumGC,
+			"gc_pause_total_ns": mem.PauseTotalNs,
+		},
+	}
+
+	files := make(map[string]string, len(sections))
+	for name, content := range sections {
+		data, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "encoding %s: %v", name, err)
+		}
+		files[name] = string(data)
+	}
+
+	return &pb.GetSupportBundleResponse{
+		Files:       files,
+		GeneratedAt: now.Unix(),
+	}, nil
+}
+
+// redactExpression hides an expression entirely, since its literals are
+// usually the customer data we are trying to keep out.
+func redactExpression(expr string) string {
+	if expr == "" {
+		return ""
+	}
+	return redacted
+}
+
+func redactValues(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k := range m {
+		out[k] = redacted
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	eventTypeControlPlaneDraining = "control_plane_draining"
+
+	// drainGracePeriod gives subscribers time to read the draining event and
+	// reconnect before the servers stop.
+	drainGracePeriod = 5 * time.Second
+)
+
+// reconnectHint tells clients where to go while this instance drains. In
+// Kubernetes the Service address is right: it routes to the replacement pod.
+func reconnectHint() string {
+	if addr := os.Getenv("TRACERY_RECONNECT_ADDR"); addr != "" {
+		return addr
+	}
+	return "control-plane:50051"
+}
+
+// drain prepares the control plane for shutdown: new subscriptions are
+// refused, and every open stream gets a control_plane_draining event with a
+// reconnect hint and is then ended with Unavailable so clients retry
+// elsewhere. A leader resigns so a standby takes over straight away.
+// Snapshots in the file store and breakpoints in Postgres survive the
+// restart; anything kept in memory has to be re-applied.
+func (s *ControlPlaneServer) drain(hint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.draining {
+		return
+	}
+	s.draining = true
+	if s.leader != nil {
+		s.leader.resign()
+	}
+
+	log.Printf("[ControlPlane] Draining %d subscriber(s), reconnect to %s", len(s.traceListeners), hint)
+
+	s.broadcast(&pb.TraceEvent{
+		Timestamp:  time.Now().Unix(),
+		EventType:  eventTypeControlPlaneDraining,
+		Attributes: map[string]string{"reconnect_to": hint},
+	})
+	for _, sub := range s.traceListeners {
+		sub.err = status.Errorf(codes.Unavailable, "control plane is shutting down; reconnect to %s", hint)
+		close(sub.ch)
+	}
+	s.traceListeners = nil
+}
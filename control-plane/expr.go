@@ -0,0 +1,731 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Breakpoint expressions are a small subset of CEL evaluated against span
+// attributes, e.g.
+//
+//	amount > 1000 && customer_id.startsWith("vip-")
+//	http.method in ["POST", "PUT"] || has(retry_count)
+//	attrs["order.id"] == "A-17"
+//
+// Attribute values are strings on the wire; comparing one against a number
+// compares numerically. Dotted names refer to a single attribute
+// ("http.method"), and attrs["..."] reaches names that aren't identifiers.
+// Referencing a missing attribute is an error, which never matches, so use
+// has() to test for optional attributes.
+
+// conditionExpr is a parsed breakpoint expression.
+type conditionExpr struct {
+	src  string
+	root exprNode
+}
+
+var errMissingAttribute = errors.New("missing attribute")
+
+// Limits on what compileCondition accepts. The parser and evaluator recurse,
+// so without them a deeply nested expression overflows the stack, which
+// takes the whole control plane down.
+const (
+	maxConditionLength = 4096
+	maxConditionDepth  = 32
+)
+
+// errConditionTooComplex is returned for expressions over the limits above.
+var errConditionTooComplex = errors.New("expression too complex")
+
+// compileCondition parses an expression so syntax errors are reported when
+// the breakpoint is registered rather than on every check.
+func compileCondition(src string) (*conditionExpr, error) {
+	if len(src) > maxConditionLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", errConditionTooComplex, maxConditionLength)
+	}
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+	return &conditionExpr{src: src, root: root}, nil
+}
+
+// Matches reports whether the expression holds for attrs. Evaluation errors
+// (missing attributes, type mismatches) count as no match.
+func (c *conditionExpr) Matches(attrs map[string]string) (bool, error) {
+	v, err := c.root.eval(attrs)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluates to %T, not bool", v)
+	}
+	return b, nil
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{tokIdent, src[start:i], start})
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokNumber, src[start:i], start})
+		case c == '"' || c == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for ; i < len(src) && rune(src[i]) != c; i++ {
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				sb.WriteByte(src[i])
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string at offset %d", start)
+			}
+			i++
+			tokens = append(tokens, token{tokString, sb.String(), start})
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ",", ".", "-"} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{tokEOF, "end of expression", len(src)}), nil
+}
+
+// Parser
+
+type exprParser struct {
+	tokens []token
+	pos    int
+	depth  int // nesting of parentheses, lists, call arguments and '!'
+}
+
+// enter descends one nesting level; the caller undoes it with leave.
+func (p *exprParser) enter() error {
+	p.depth++
+	if p.depth > maxConditionDepth {
+		return fmt.Errorf("%w: nested deeper than %d levels at offset %d", errConditionTooComplex, maxConditionDepth, p.peek().pos)
+	}
+	return nil
+}
+
+func (p *exprParser) leave() {
+	p.depth--
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) acceptOp(op string) bool {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expectOp(op string) error {
+	if !p.acceptOp(op) {
+		tok := p.peek()
+		return fmt.Errorf("expected %q but found %q at offset %d", op, tok.text, tok.pos)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseRelation()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("&&") {
+		right, err := p.parseRelation()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+var comparisonOps = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+func (p *exprParser) parseRelation() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokOp && comparisonOps[tok.text]:
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if isList(left) || isList(right) {
+			return nil, fmt.Errorf("lists cannot be compared with %s at offset %d; use in", tok.text, tok.pos)
+		}
+		return &compareNode{op: tok.text, left: left, right: right}, nil
+	case tok.kind == tokIdent && tok.text == "in":
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &inNode{left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func isList(n exprNode) bool {
+	_, ok := n.(*listNode)
+	return ok
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.acceptOp("!") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	if p.acceptOp("-") {
+		tok := p.next()
+		if tok.kind != tokNumber {
+			return nil, fmt.Errorf("expected a number after '-' at offset %d", tok.pos)
+		}
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return &literalNode{value: -n}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (exprNode, error) {
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptOp(".") {
+		tok := p.next()
+		if tok.kind != tokIdent {
+			return nil, fmt.Errorf("expected a name after '.' at offset %d", tok.pos)
+		}
+
+		if p.acceptOp("(") {
+			args, err := p.parseArgs(")")
+			if err != nil {
+				return nil, err
+			}
+			node, err = newCallNode(tok.text, node, args)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		attr, ok := node.(*attrNode)
+		if !ok {
+			return nil, fmt.Errorf("field selection %q is only supported on attribute names", tok.text)
+		}
+		node = &attrNode{name: attr.name + "." + tok.text}
+	}
+	return node, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return &literalNode{value: n}, nil
+	case tokString:
+		return &literalNode{value: tok.text}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "attrs":
+			if p.acceptOp("[") {
+				key := p.next()
+				if key.kind != tokString {
+					return nil, fmt.Errorf("attrs[...] takes a string key at offset %d", key.pos)
+				}
+				if err := p.expectOp("]"); err != nil {
+					return nil, err
+				}
+				return &attrNode{name: key.text}, nil
+			}
+		}
+		if p.acceptOp("(") {
+			args, err := p.parseArgs(")")
+			if err != nil {
+				return nil, err
+			}
+			return newCallNode(tok.text, nil, args)
+		}
+		return &attrNode{name: tok.text}, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expectOp(")")
+		case "[":
+			items, err := p.parseArgs("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+}
+
+func (p *exprParser) parseArgs(closing string) ([]exprNode, error) {
+	var args []exprNode
+	if p.acceptOp(closing) {
+		return args, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.acceptOp(closing) {
+			return args, nil
+		}
+		if err := p.expectOp(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Evaluation
+
+type exprNode interface {
+	eval(attrs map[string]string) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(map[string]string) (interface{}, error) {
+	return n.value, nil
+}
+
+type attrNode struct {
+	name string
+}
+
+func (n *attrNode) eval(attrs map[string]string) (interface{}, error) {
+	v, ok := attrs[n.name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errMissingAttribute, n.name)
+	}
+	return v, nil
+}
+
+type listNode struct {
+	items []exprNode
+}
+
+func (n *listNode) eval(attrs map[string]string) (interface{}, error) {
+	out := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(attrs)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+type notNode struct {
+	operand exprNode
+}
+
+func (n *notNode) eval(attrs map[string]string) (interface{}, error) {
+	b, err := evalBool(n.operand, attrs)
+	if err != nil {
+		return nil, err
+	}
+	return !b, nil
+}
+
+// logicalNode implements && and || with CEL's commutative error handling:
+// a decisive value on either side wins even if the other side failed.
+type logicalNode struct {
+	or          bool
+	left, right exprNode
+}
+
+func (n *logicalNode) eval(attrs map[string]string) (interface{}, error) {
+	left, leftErr := evalBool(n.left, attrs)
+	if leftErr == nil && left == n.or {
+		return n.or, nil
+	}
+	right, rightErr := evalBool(n.right, attrs)
+	if rightErr == nil && right == n.or {
+		return n.or, nil
+	}
+	if leftErr != nil {
+		return nil, leftErr
+	}
+	if rightErr != nil {
+		return nil, rightErr
+	}
+	return !n.or, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *compareNode) eval(attrs map[string]string) (interface{}, error) {
+	left, err := n.left.eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return valuesEqual(left, right), nil
+	case "!=":
+		return !valuesEqual(left, right), nil
+	}
+
+	cmp, err := compareValues(left, right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+type inNode struct {
+	left, right exprNode
+}
+
+func (n *inNode) eval(attrs map[string]string) (interface{}, error) {
+	left, err := n.left.eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(attrs)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := right.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("right side of 'in' must be a list")
+	}
+	for _, item := range list {
+		if valuesEqual(left, item) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type callNode struct {
+	fn   string
+	recv exprNode
+	args []exprNode
+	re   *regexp.Regexp // precompiled when matches() gets a literal pattern
+}
+
+func newCallNode(fn string, recv exprNode, args []exprNode) (exprNode, error) {
+	n := &callNode{fn: fn, recv: recv, args: args}
+	switch {
+	case recv != nil && (fn == "startsWith" || fn == "endsWith" || fn == "contains" || fn == "matches"):
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s takes one argument", fn)
+		}
+		if lit, ok := args[0].(*literalNode); ok && fn == "matches" {
+			pattern, ok := lit.value.(string)
+			if !ok {
+				return nil, fmt.Errorf("matches takes a string pattern")
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+			}
+			n.re = re
+		}
+	case recv == nil && fn == "has":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("has takes one attribute name")
+		}
+		if _, ok := args[0].(*attrNode); !ok {
+			return nil, fmt.Errorf("has takes an attribute name")
+		}
+	case recv == nil && fn == "size":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("size takes one argument")
+		}
+	default:
+		return nil, fmt.Errorf("unknown function %q", fn)
+	}
+	return n, nil
+}
+
+func (n *callNode) eval(attrs map[string]string) (interface{}, error) {
+	switch n.fn {
+	case "has":
+		_, ok := attrs[n.args[0].(*attrNode).name]
+		return ok, nil
+	case "size":
+		v, err := n.args[0].eval(attrs)
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case string:
+			return float64(len(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("size of %T", v)
+	}
+
+	recv, err := evalString(n.recv, attrs)
+	if err != nil {
+		return nil, err
+	}
+	if n.re != nil {
+		return n.re.MatchString(recv), nil
+	}
+	arg, err := evalString(n.args[0], attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.fn {
+	case "startsWith":
+		return strings.HasPrefix(recv, arg), nil
+	case "endsWith":
+		return strings.HasSuffix(recv, arg), nil
+	case "contains":
+		return strings.Contains(recv, arg), nil
+	default:
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(recv), nil
+	}
+}
+
+func evalBool(n exprNode, attrs map[string]string) (bool, error) {
+	v, err := n.eval(attrs)
+	if err != nil {
+		return false, err
+	}
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		// Boolean attributes arrive as "true"/"false".
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("expected a bool, got %v", v)
+}
+
+func evalString(n exprNode, attrs map[string]string) (string, error) {
+	v, err := n.eval(attrs)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expected a string, got %v", v)
+	}
+	return s, nil
+}
+
+// valuesEqual compares loosely typed values. A string attribute equals a
+// number or bool literal when it parses to that value. Lists are never
+// equal to anything; comparing them with == would panic.
+func valuesEqual(a, b interface{}) bool {
+	switch a.(type) {
+	case string, float64, bool:
+		if a == b {
+			return true
+		}
+	}
+	switch x := a.(type) {
+	case string:
+		switch y := b.(type) {
+		case float64:
+			n, err := strconv.ParseFloat(x, 64)
+			return err == nil && n == y
+		case bool:
+			v, err := strconv.ParseBool(x)
+			return err == nil && v == y
+		}
+	case float64, bool:
+		if _, ok := b.(string); ok {
+			return valuesEqual(b, a)
+		}
+	}
+	return false
+}
+
+// compareValues orders two values numerically when either side is a
+// number, and lexically when both are strings.
+func compareValues(a, b interface{}) (int, error) {
+	as, aStr := a.(string)
+	bs, bStr := b.(string)
+	if aStr && bStr {
+		return strings.Compare(as, bs), nil
+	}
+
+	x, err := toNumber(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toNumber(b)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case x < y:
+		return -1, nil
+	case x > y:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func toNumber(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v)
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCompileConditionRejectsListComparisons(t *testing.T) {
+	for _, src := range []string{
+		"[1] == [1]",
+		"[1] != [2]",
+		"amount < [1, 2]",
+		"([1]) >= amount",
+	} {
+		if _, err := compileCondition(src); err == nil {
+			t.Errorf("compileCondition(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestListsStillWorkWithIn(t *testing.T) {
+	expr, err := compileCondition(`region in ["eu", "us"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := expr.Matches(map[string]string{"region": "eu"})
+	if err != nil || !ok {
+		t.Errorf("Matches = %v, %v; want true", ok, err)
+	}
+}
+
+func TestValuesEqualDoesNotPanicOnLists(t *testing.T) {
+	list := []interface{}{1.0}
+	if valuesEqual(list, list) {
+		t.Error("a list compared equal")
+	}
+	if valuesEqual("a", list) || valuesEqual(list, "a") {
+		t.Error("a string compared equal to a list")
+	}
+}
+
+func TestConditionMatches(t *testing.T) {
+	attrs := map[string]string{
+		"amount":      "1500",
+		"customer_id": "vip-42",
+		"http.method": "POST",
+		"order.id":    "A-17",
+		"retry":       "true",
+		"region":      "eu-west-1",
+	}
+	tests := []struct {
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{expr: `amount > 1000 && customer_id.startsWith("vip-")`, want: true},
+		{expr: `amount > 2000 && customer_id.startsWith("vip-")`, want: false},
+		{expr: `amount > 1000 && customer_id.startsWith("std-")`, want: false},
+
+		{expr: `http.method in ["POST", "PUT"]`, want: true},
+		{expr: `http.method in ["GET"]`, want: false},
+		{expr: `amount in [1000, 1500]`, want: true},
+
+		{expr: `has(retry)`, want: true},
+		{expr: `has(coupon)`, want: false},
+		{expr: `has(coupon) || amount >= 1500`, want: true},
+
+		{expr: `region.matches("^eu-")`, want: true},
+		{expr: `region.matches("^us-")`, want: false},
+		{expr: `attrs["order.id"].matches("A-[0-9]+")`, want: true},
+
+		// A string attribute compares numerically against a number, and
+		// lexically against a string.
+		{expr: `amount == 1500`, want: true},
+		{expr: `amount == 1500.0`, want: true},
+		{expr: `amount < 200`, want: false},
+		{expr: `amount < "200"`, want: true},
+		{expr: `retry == true`, want: true},
+		{expr: `retry && !(amount < 0)`, want: true},
+		{expr: `customer_id > 10`, wantErr: true},
+
+		// Missing attributes are an error, which never matches.
+		{expr: `coupon == "SAVE10"`, wantErr: true},
+		{expr: `coupon.startsWith("SAVE")`, wantErr: true},
+		{expr: `!has(coupon) && amount > 1000`, want: true},
+	}
+	for _, tt := range tests {
+		expr, err := compileCondition(tt.expr)
+		if err != nil {
+			t.Errorf("compileCondition(%q): %v", tt.expr, err)
+			continue
+		}
+		got, err := expr.Matches(attrs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestMissingAttributeError(t *testing.T) {
+	expr, err := compileCondition(`coupon == "SAVE10"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expr.Matches(map[string]string{}); !errors.Is(err, errMissingAttribute) {
+		t.Errorf("Matches error = %v, want errMissingAttribute", err)
+	}
+}
+
+func TestCompileConditionErrors(t *testing.T) {
+	for _, src := range []string{
+		`amount >`,
+		`(amount > 1`,
+		`amount > 1 amount`,
+		`customer_id.startsWith()`,
+		`has("coupon")`,
+		`region.matches("[")`,
+		`unknown(amount)`,
+		`"unterminated`,
+		`amount ~ 1`,
+	} {
+		if _, err := compileCondition(src); err == nil {
+			t.Errorf("compileCondition(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestCompileConditionLimits(t *testing.T) {
+	ok := strings.Repeat("(", maxConditionDepth-1) + "amount > 1" + strings.Repeat(")", maxConditionDepth-1)
+	if _, err := compileCondition(ok); err != nil {
+		t.Errorf("%d levels of nesting: %v", maxConditionDepth-1, err)
+	}
+
+	for name, src := range map[string]string{
+		"parentheses": strings.Repeat("(", maxConditionDepth) + "amount > 1" + strings.Repeat(")", maxConditionDepth),
+		"negation":    strings.Repeat("!", maxConditionDepth+1) + "retry",
+		"lists":       "amount in " + strings.Repeat("[", maxConditionDepth+1) + strings.Repeat("]", maxConditionDepth+1),
+		"length":      "amount > 1" + strings.Repeat(" ", maxConditionLength),
+		// Deep enough to overflow the stack without the limits.
+		"huge": strings.Repeat("(", 2<<20),
+	} {
+		if _, err := compileCondition(src); !errors.Is(err, errConditionTooComplex) {
+			t.Errorf("%s: err = %v, want errConditionTooComplex", name, err)
+		}
+	}
+}
+
+func TestRegisterBreakpointRejectsInvalidExpressions(t *testing.T) {
+	s := NewControlPlaneServer(nil, nil)
+
+	resp, err := s.RegisterBreakpoint(context.Background(), &pb.RegisterBreakPointRequest{
+		ServiceName: "order-processing",
+		Expression:  `amount >`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetSuccess() || !strings.HasPrefix(resp.GetRespMessage(), "invalid expression: ") {
+		t.Errorf("syntax error: got %v", resp)
+	}
+
+	_, err = s.RegisterBreakpoint(context.Background(), &pb.RegisterBreakPointRequest{
+		ServiceName: "order-processing",
+		Expression:  strings.Repeat("(", maxConditionDepth+1) + "amount > 1" + strings.Repeat(")", maxConditionDepth+1),
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("too deep: err = %v, want InvalidArgument", err)
+	}
+
+	if len(s.breakPoints) != 0 {
+		t.Errorf("%d breakpoint(s) registered, want none", len(s.breakPoints))
+	}
+}
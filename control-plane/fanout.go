@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+)
+
+// minFanoutBatch is the fewest subscribers worth handing to a worker.
+// Delivery is a non-blocking channel send, so below this the handoff costs
+// more than it saves.
+const minFanoutBatch = 64
+
+// fanout delivers broadcast events on a fixed pool of workers, so the time
+// broadcast holds s.mu stops growing linearly with the number of open
+// streams.
+type fanout struct {
+	workers int
+	batches chan fanoutBatch
+}
+
+// fanoutBatch is one worker's share of a broadcast. keep[i] reports whether
+// subs[i] stays subscribed.
+type fanoutBatch struct {
+	event *pb.TraceEvent
+	subs  []*subscriber
+	keep  []bool
+	done  *sync.WaitGroup
+}
+
+// broadcastWorkersFromEnv reads TRACERY_BROADCAST_WORKERS, the number of
+// goroutines broadcast fans events out on. Unset, 0 or 1 keeps delivery
+// inline on the broadcasting goroutine.
+func broadcastWorkersFromEnv() (int, error) {
+	v := os.Getenv("TRACERY_BROADCAST_WORKERS")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("TRACERY_BROADCAST_WORKERS: want a non-negative number of workers, got %q", v)
+	}
+	return n, nil
+}
+
+// newFanout starts a pool of workers, or returns nil when one goroutine is
+// all that was asked for.
+func newFanout(workers int) *fanout {
+	if workers <= 1 {
+		return nil
+	}
+	f := &fanout{
+		workers: workers,
+		batches: make(chan fanoutBatch, workers),
+	}
+	for i := 0; i < workers; i++ {
+		go f.run()
+	}
+	log.Printf("[ControlPlane] Fanning out broadcasts on %d workers", workers)
+	return f
+}
+
+func (f *fanout) run() {
+	for b := range f.batches {
+		for i, sub := range b.subs {
+			b.keep[i] = !sub.wants(b.event) || sub.deliver(b.event)
+		}
+		b.done.Done()
+	}
+}
+
+// deliver hands event to every subscriber and reports which of them stay
+// subscribed. Each subscriber is in exactly one batch, so events still
+// reach a subscriber in broadcast order. A nil fanout delivers inline.
+func (f *fanout) deliver(event *pb.TraceEvent, subs []*subscriber) []bool {
+	keep := make([]bool, len(subs))
+	if f == nil || len(subs) < 2*minFanoutBatch {
+		for i, sub := range subs {
+			keep[i] = !sub.wants(event) || sub.deliver(event)
+		}
+		return keep
+	}
+
+	size := (len(subs) + f.workers - 1) / f.workers
+	if size < minFanoutBatch {
+		size = minFanoutBatch
+	}
+	var done sync.WaitGroup
+	for start := 0; start < len(subs); start += size {
+		end := min(start+size, len(subs))
+		done.Add(1)
+		f.batches <- fanoutBatch{event: event, subs: subs[start:end], keep: keep[start:end], done: &done}
+	}
+	done.Wait()
+	return keep
+}
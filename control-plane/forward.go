@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// forwardQueueSize is how many received batches may wait for the
+	// downstream backend before new ones are dropped.
+	forwardQueueSize = 1000
+
+	forwardTimeout = 10 * time.Second
+)
+
+// otlpForwarder passes every batch of spans the control plane receives on
+// to a downstream OTLP backend such as Tempo or Jaeger, so the control
+// plane can sit in the collector path without swallowing traces. Exports
+// happen in the background; a slow or unreachable backend costs dropped
+// batches, never ingestion latency.
+type otlpForwarder struct {
+	endpoint string
+	conn     *grpc.ClientConn
+	client   ptraceotlp.GRPCClient
+	headers  metadata.MD
+	done     chan struct{}
+
+	mu     sync.RWMutex // guards sends on queue against stop closing it
+	queue  chan ptrace.Traces
+	closed bool
+
+	forwarded atomic.Int64
+	dropped   atomic.Int64 // queue full or export failed
+}
+
+// newOTLPForwarderFromEnv forwards to TRACERY_OTLP_FORWARD_ENDPOINT
+// (host:port), configured as described at dialOTLPFromEnv. It returns nil
+// when no endpoint is set.
+func newOTLPForwarderFromEnv() (*otlpForwarder, error) {
+	conn, endpoint, headers, err := dialOTLPFromEnv("TRACERY_OTLP_FORWARD")
+	if conn == nil || err != nil {
+		return nil, err
+	}
+
+	f := &otlpForwarder{
+		endpoint: endpoint,
+		conn:     conn,
+		client:   ptraceotlp.NewGRPCClient(conn),
+		headers:  headers,
+		queue:    make(chan ptrace.Traces, forwardQueueSize),
+		done:     make(chan struct{}),
+	}
+	go f.run()
+	log.Printf("[ControlPlane] Forwarding received spans to %s", endpoint)
+	return f, nil
+}
+
+// forward queues a copy of traces for export. The copy keeps the batch
+// independent of ingestion. It never blocks and is a no-op on a nil
+// forwarder.
+func (f *otlpForwarder) forward(traces ptrace.Traces) {
+	if f == nil {
+		return
+	}
+	batch := ptrace.NewTraces()
+	traces.CopyTo(batch)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return
+	}
+	select {
+	case f.queue <- batch:
+	default:
+		if f.dropped.Add(1)%100 == 1 {
+			log.Printf("[ControlPlane] Forward queue to %s is full, dropping spans (%d batches dropped)", f.endpoint, f.dropped.Load())
+		}
+	}
+}
+
+func (f *otlpForwarder) run() {
+	defer close(f.done)
+	for batch := range f.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), forwardTimeout)
+		if len(f.headers) > 0 {
+			ctx = metadata.NewOutgoingContext(ctx, f.headers)
+		}
+		_, err := f.client.Export(ctx, ptraceotlp.NewExportRequestFromTraces(batch))
+		cancel()
+		if err != nil {
+			if f.dropped.Add(1)%100 == 1 {
+				log.Printf("[ControlPlane] Failed to forward spans to %s: %v (%d batches dropped)", f.endpoint, err, f.dropped.Load())
+			}
+			continue
+		}
+		f.forwarded.Add(1)
+	}
+}
+
+// stop exports what is already queued, giving up after timeout, and closes
+// the connection. Spans received afterwards are not forwarded.
+func (f *otlpForwarder) stop(timeout time.Duration) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	if !f.closed {
+		f.closed = true
+		close(f.queue)
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-f.done:
+	case <-time.After(timeout):
+		log.Printf("[ControlPlane] Gave up forwarding %d queued batches to %s", len(f.queue), f.endpoint)
+	}
+	f.conn.Close()
+}
+
+// dialOTLPFromEnv connects to the OTLP/gRPC endpoint in <prefix>_ENDPOINT.
+// <prefix>_TLS=true dials with TLS, and <prefix>_HEADERS holds
+// comma-separated key=value headers to send with every export, e.g.
+// X-Scope-OrgID=team-a for a multi-tenant backend. The connection is nil
+// when no endpoint is set.
+func dialOTLPFromEnv(prefix string) (*grpc.ClientConn, string, metadata.MD, error) {
+	endpoint := os.Getenv(prefix + "_ENDPOINT")
+	if endpoint == "" {
+		return nil, "", nil, nil
+	}
+
+	creds := insecure.NewCredentials()
+	if os.Getenv(prefix+"_TLS") == "true" {
+		creds = credentials.NewClientTLSFromCert(nil, "")
+	}
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	headers := metadata.MD{}
+	for _, pair := range strings.Split(os.Getenv(prefix+"_HEADERS"), ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(k) != "" {
+			headers.Append(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+	}
+	return conn, endpoint, headers, nil
+}
+
+// stats summarises forwarding for support bundles; nil when spans are not
+// forwarded.
+func (f *otlpForwarder) stats() map[string]interface{} {
+	if f == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"endpoint":  f.endpoint,
+		"queued":    len(f.queue),
+		"forwarded": f.forwarded.Load(),
+		"dropped":   f.dropped.Load(),
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// httpHandler serves the control plane's HTTP endpoints: health checks,
+// metrics and inbound posts from SDKs and systems that can't speak gRPC.
+func (s *ControlPlaneServer) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/webhooks/alertmanager", s.handleAlertmanagerWebhook)
+	mux.HandleFunc("/app-snapshot", s.handleAppSnapshot)
+	mux.HandleFunc("/api/v2/spans", s.handleZipkinSpans)
+	return mux
+}
+
+func (s *ControlPlaneServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	breakpoints := len(s.breakPoints)
+	draining := s.draining
+	failing := s.failingSelfChecks()
+	lastSpanAt := s.lastSpanAt
+	s.mu.RUnlock()
+
+	// Failing health checks while draining takes the pod out of rotation.
+	if draining {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+			"status":       "draining",
+			"reconnect_to": reconnectHint(),
+		})
+		return
+	}
+
+	// Failing self-checks are reported but keep the pod in rotation: a
+	// degraded debugger is still more useful than none.
+	health := "healthy"
+	if len(failing) > 0 {
+		health = "degraded"
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":         health,
+		"breakpoints":    breakpoints,
+		"leader":         s.isLeader(),
+		"failing_checks": failing,
+		"last_span_at":   unixOrZero(lastSpanAt),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
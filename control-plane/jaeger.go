@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	jaeger "github.com/Aneesh-Hegde/tracery/controlplane/proto/jaeger"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// jaegerReceiver accepts spans on the Jaeger collector gRPC API, which is
+// what Jaeger agents and Jaeger-native clients send to. Batches are
+// translated to OTLP and go through the same path as OTLP exports.
+type jaegerReceiver struct {
+	jaeger.UnimplementedCollectorServiceServer
+	cp *ControlPlaneServer
+}
+
+func newJaegerReceiver(cp *ControlPlaneServer) *jaegerReceiver {
+	return &jaegerReceiver{cp: cp}
+}
+
+func (r *jaegerReceiver) PostSpans(ctx context.Context, req *jaeger.PostSpansRequest) (*jaeger.PostSpansResponse, error) {
+	r.cp.ingestTraces(jaegerBatchToTraces(req.GetBatch()))
+	return &jaeger.PostSpansResponse{}, nil
+}
+
+// jaegerBatchToTraces converts a Jaeger batch the way the OpenTelemetry
+// collector's Jaeger receiver does: the process becomes the resource, the
+// first CHILD_OF reference the parent, other references links, logs span
+// events, and the span.kind, error and otel.status_* tags the span's kind
+// and status.
+func jaegerBatchToTraces(batch *jaeger.Batch) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	for _, js := range batch.GetSpans() {
+		process := js.GetProcess()
+		if process == nil {
+			process = batch.GetProcess()
+		}
+
+		rs := traces.ResourceSpans().AppendEmpty()
+		resource := rs.Resource().Attributes()
+		resource.PutStr("service.name", process.GetServiceName())
+		putJaegerTags(resource, process.GetTags())
+
+		span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		traceID := jaegerTraceID(js.GetTraceId())
+		span.SetTraceID(traceID)
+		span.SetSpanID(jaegerSpanID(js.GetSpanId()))
+		span.SetName(js.GetOperationName())
+
+		start := js.GetStartTime().AsTime()
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(js.GetDuration().AsDuration())))
+
+		parentSet := false
+		for _, ref := range js.GetReferences() {
+			refTraceID := jaegerTraceID(ref.GetTraceId())
+			if !parentSet && ref.GetRefType() == jaeger.SpanRefType_CHILD_OF && refTraceID == traceID {
+				span.SetParentSpanID(jaegerSpanID(ref.GetSpanId()))
+				parentSet = true
+				continue
+			}
+			link := span.Links().AppendEmpty()
+			link.SetTraceID(refTraceID)
+			link.SetSpanID(jaegerSpanID(ref.GetSpanId()))
+		}
+
+		attrs := span.Attributes()
+		for _, tag := range js.GetTags() {
+			switch tag.GetKey() {
+			case "span.kind":
+				span.SetKind(jaegerSpanKind(tag.GetVStr()))
+			case "error":
+				if tag.GetVBool() || tag.GetVStr() == "true" {
+					span.Status().SetCode(ptrace.StatusCodeError)
+				}
+			case "otel.status_code":
+				switch strings.ToUpper(tag.GetVStr()) {
+				case "ERROR":
+					span.Status().SetCode(ptrace.StatusCodeError)
+				case "OK":
+					span.Status().SetCode(ptrace.StatusCodeOk)
+				}
+			case "otel.status_description":
+				span.Status().SetMessage(tag.GetVStr())
+			default:
+				putJaegerTag(attrs, tag)
+			}
+		}
+
+		for _, jl := range js.GetLogs() {
+			event := span.Events().AppendEmpty()
+			event.SetTimestamp(pcommon.NewTimestampFromTime(jl.GetTimestamp().AsTime()))
+			event.SetName("log")
+			for _, field := range jl.GetFields() {
+				if field.GetKey() == "event" && field.GetVType() == jaeger.ValueType_STRING {
+					event.SetName(field.GetVStr())
+					continue
+				}
+				putJaegerTag(event.Attributes(), field)
+			}
+		}
+	}
+	return traces
+}
+
+func putJaegerTags(m pcommon.Map, tags []*jaeger.KeyValue) {
+	for _, tag := range tags {
+		putJaegerTag(m, tag)
+	}
+}
+
+func putJaegerTag(m pcommon.Map, tag *jaeger.KeyValue) {
+	switch tag.GetVType() {
+	case jaeger.ValueType_BOOL:
+		m.PutBool(tag.GetKey(), tag.GetVBool())
+	case jaeger.ValueType_INT64:
+		m.PutInt(tag.GetKey(), tag.GetVInt64())
+	case jaeger.ValueType_FLOAT64:
+		m.PutDouble(tag.GetKey(), tag.GetVFloat64())
+	case jaeger.ValueType_BINARY:
+		m.PutEmptyBytes(tag.GetKey()).FromRaw(tag.GetVBinary())
+	default:
+		m.PutStr(tag.GetKey(), tag.GetVStr())
+	}
+}
+
+// jaegerTraceID right-aligns a Jaeger trace ID, which is 8 bytes for
+// clients that only use the low half.
+func jaegerTraceID(b []byte) pcommon.TraceID {
+	var id pcommon.TraceID
+	if len(b) <= len(id) {
+		copy(id[len(id)-len(b):], b)
+	}
+	return id
+}
+
+func jaegerSpanID(b []byte) pcommon.SpanID {
+	var id pcommon.SpanID
+	if len(b) <= len(id) {
+		copy(id[len(id)-len(b):], b)
+	}
+	return id
+}
+
+func jaegerSpanKind(kind string) ptrace.SpanKind {
+	switch kind {
+	case "server":
+		return ptrace.SpanKindServer
+	case "client":
+		return ptrace.SpanKindClient
+	case "producer":
+		return ptrace.SpanKindProducer
+	case "consumer":
+		return ptrace.SpanKindConsumer
+	case "internal":
+		return ptrace.SpanKindInternal
+	}
+	return ptrace.SpanKindUnspecified
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// leaderLockKey is the Postgres advisory lock replicas compete for.
+	leaderLockKey int64 = 0x7472616365727931
+
+	// leaderElectionInterval is how often a standby retries the lock and
+	// the leader checks it still holds it.
+	leaderElectionInterval = 5 * time.Second
+)
+
+// leaderElector elects one leader among replicas sharing a Postgres
+// breakpoint store. The leader holds a session-level advisory lock on a
+// dedicated connection, so the lock is released as soon as that connection
+// drops and a standby takes over on its next attempt.
+type leaderElector struct {
+	db *sql.DB
+
+	mu       sync.Mutex
+	conn     *sql.Conn
+	leader   bool
+	resigned bool
+}
+
+func newLeaderElector(db *sql.DB) *leaderElector {
+	return &leaderElector{db: db}
+}
+
+// IsLeader reports whether this replica currently holds the lock.
+func (l *leaderElector) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leader
+}
+
+func (l *leaderElector) run(interval time.Duration) {
+	l.elect()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		l.elect()
+	}
+}
+
+// elect tries to take the lock as a standby, or confirms the connection
+// holding it is still alive as the leader.
+func (l *leaderElector) elect() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.resigned {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), leaderElectionInterval)
+	defer cancel()
+
+	if l.conn == nil {
+		conn, err := l.db.Conn(ctx)
+		if err != nil {
+			log.Printf("[ControlPlane] Leader election: %v", err)
+			return
+		}
+		l.conn = conn
+	}
+
+	if l.leader {
+		if err := l.conn.PingContext(ctx); err != nil {
+			log.Printf("[ControlPlane] Lost leadership: %v", err)
+			l.dropConn()
+		}
+		return
+	}
+
+	var acquired bool
+	if err := l.conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, leaderLockKey).Scan(&acquired); err != nil {
+		log.Printf("[ControlPlane] Leader election: %v", err)
+		l.dropConn()
+		return
+	}
+	if acquired {
+		l.leader = true
+		log.Printf("[ControlPlane] Became leader")
+	}
+}
+
+// resign gives up leadership for good so a standby can take over without
+// waiting for this replica's connection to time out.
+func (l *leaderElector) resign() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.resigned = true
+	if l.leader {
+		log.Printf("[ControlPlane] Resigning leadership")
+	}
+	l.dropConn()
+}
+
+// dropConn closes the lock connection, which releases the lock if held.
+// Callers must hold l.mu.
+func (l *leaderElector) dropConn() {
+	if l.conn != nil {
+		l.conn.Close()
+		l.conn = nil
+	}
+	l.leader = false
+}
+
+// isLeader reports whether this replica should do cluster-wide work such as
+// expiring breakpoints. A replica without an elector runs alone and always
+// leads.
+func (s *ControlPlaneServer) isLeader() bool {
+	return s.leader == nil || s.leader.IsLeader()
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// logExportClass is the subscriber class the log exporter reads events
+	// through. It drops the oldest events rather than being disconnected
+	// when the logging backend falls behind.
+	logExportClass = "otlp_logs"
+
+	logExportBatchSize = 100
+	logExportInterval  = time.Second
+)
+
+// logExporter ships control-plane events to a logging backend as OTLP log
+// records, so breakpoint hits and snapshots show up next to the services'
+// own logs and link to their traces. Span events are left out; they are
+// already in the tracing backend.
+type logExporter struct {
+	cp       *ControlPlaneServer
+	endpoint string
+	conn     *grpc.ClientConn
+	client   plogotlp.GRPCClient
+	headers  metadata.MD
+	done     chan struct{}
+
+	exported atomic.Int64
+	failed   atomic.Int64
+}
+
+// startLogExporterFromEnv exports to TRACERY_OTLP_LOGS_ENDPOINT, configured
+// as described at dialOTLPFromEnv. It returns nil when no endpoint is set.
+func (s *ControlPlaneServer) startLogExporterFromEnv() (*logExporter, error) {
+	conn, endpoint, headers, err := dialOTLPFromEnv("TRACERY_OTLP_LOGS")
+	if conn == nil || err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.subscriberClasses[logExportClass] = &subscriberClass{Name: logExportClass, BufferSize: 10000, DropPolicy: dropOldest}
+	s.mu.Unlock()
+
+	sub, _, err := s.subscribe(logExportClass, "", []string{
+		eventTypeBreakpointHit, eventTypeSnapshotRecorded,
+		eventTypeBreakpointRegistered, eventTypeBreakpointUpdated, eventTypeBreakpointDeleted,
+		eventTypeBreakpointEnabled, eventTypeBreakpointDisabled, eventTypeBreakpointExpired,
+		eventTypeSelfCheckFailed, eventTypeSelfCheckRecovered, eventTypeControlPlaneDraining,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	e := &logExporter{
+		cp:       s,
+		endpoint: endpoint,
+		conn:     conn,
+		client:   plogotlp.NewGRPCClient(conn),
+		headers:  headers,
+		done:     make(chan struct{}),
+	}
+	go e.run(sub)
+	log.Printf("[ControlPlane] Exporting events as OTLP logs to %s", endpoint)
+	return e, nil
+}
+
+// run batches events until the subscription ends, which happens when the
+// control plane drains.
+func (e *logExporter) run(sub *subscriber) {
+	defer close(e.done)
+	defer e.conn.Close()
+
+	ticker := time.NewTicker(logExportInterval)
+	defer ticker.Stop()
+
+	batch := plog.NewLogs()
+	for {
+		select {
+		case event, ok := <-sub.ch:
+			if !ok {
+				e.flush(batch)
+				return
+			}
+			e.append(batch, event)
+			if batch.LogRecordCount() >= logExportBatchSize {
+				e.flush(batch)
+				batch = plog.NewLogs()
+			}
+		case <-ticker.C:
+			if batch.LogRecordCount() > 0 {
+				e.flush(batch)
+				batch = plog.NewLogs()
+			}
+		}
+	}
+}
+
+func (e *logExporter) flush(batch plog.Logs) {
+	n := batch.LogRecordCount()
+	if n == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), forwardTimeout)
+	defer cancel()
+	if len(e.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	}
+	if _, err := e.client.Export(ctx, plogotlp.NewExportRequestFromLogs(batch)); err != nil {
+		e.failed.Add(int64(n))
+		log.Printf("[ControlPlane] Failed to export %d log record(s) to %s: %v", n, e.endpoint, err)
+		return
+	}
+	e.exported.Add(int64(n))
+}
+
+// append adds event to batch as a log record under a resource for the
+// service the event is about, so backends file it with that service's logs.
+func (e *logExporter) append(batch plog.Logs, event *pb.TraceEvent) {
+	service := event.GetServiceName()
+	if service == "" {
+		service = "tracery-control-plane"
+	}
+
+	var records plog.LogRecordSlice
+	found := false
+	for i := 0; i < batch.ResourceLogs().Len() && !found; i++ {
+		rl := batch.ResourceLogs().At(i)
+		if v, _ := rl.Resource().Attributes().Get("service.name"); v.AsString() == service {
+			records = rl.ScopeLogs().At(0).LogRecords()
+			found = true
+		}
+	}
+	if !found {
+		rl := batch.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("service.name", service)
+		scope := rl.ScopeLogs().AppendEmpty()
+		scope.Scope().SetName("tracery")
+		records = scope.LogRecords()
+	}
+
+	record := records.AppendEmpty()
+	record.SetEventName("tracery." + event.GetEventType())
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(event.GetTimestamp(), 0)))
+	record.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	record.SetSeverityNumber(plog.SeverityNumberInfo)
+	record.SetSeverityText("INFO")
+	if event.GetEventType() == eventTypeSelfCheckFailed || event.GetEventType() == eventTypeControlPlaneDraining {
+		record.SetSeverityNumber(plog.SeverityNumberWarn)
+		record.SetSeverityText("WARN")
+	}
+
+	if id, ok := parseTraceID(event.GetTraceId()); ok {
+		record.SetTraceID(id)
+	}
+	if id, ok := parseSpanID(event.GetSpan().GetSpanId()); ok {
+		record.SetSpanID(id)
+	}
+
+	attrs := record.Attributes()
+	attrs.PutStr("tracery.event_type", event.GetEventType())
+	if event.GetTraceId() != "" {
+		attrs.PutStr("tracery.trace_id", event.GetTraceId())
+	}
+	if event.GetEndpoint() != "" {
+		attrs.PutStr("tracery.endpoint", event.GetEndpoint())
+	}
+	if event.GetBreakpointId() != "" {
+		attrs.PutStr("tracery.breakpoint_id", event.GetBreakpointId())
+	}
+	for k, v := range event.GetAttributes() {
+		attrs.PutStr(k, v)
+	}
+
+	record.Body().SetStr(e.body(event))
+}
+
+// body is the human-readable part of a record. For a snapshot it is the
+// captured data itself, looked up in the snapshot store.
+func (e *logExporter) body(event *pb.TraceEvent) string {
+	switch event.GetEventType() {
+	case eventTypeBreakpointHit:
+		return fmt.Sprintf("Breakpoint %s hit at %s%s", event.GetBreakpointId(), event.GetServiceName(), event.GetEndpoint())
+	case eventTypeSnapshotRecorded:
+		id := event.GetAttributes()["snapshot_id"]
+		snaps, err := e.cp.snapshots.Get(event.GetTraceId(), event.GetServiceName())
+		if err == nil {
+			for _, snap := range snaps {
+				if snap.ID == id {
+					return snap.Data
+				}
+			}
+		}
+		return fmt.Sprintf("Snapshot %s recorded at %s%s", id, event.GetServiceName(), event.GetEndpoint())
+	}
+	return fmt.Sprintf("%s %s%s", event.GetEventType(), event.GetServiceName(), event.GetEndpoint())
+}
+
+// wait blocks until the exporter has flushed after the control plane
+// drained, or timeout passes. It is a no-op on a nil exporter.
+func (e *logExporter) wait(timeout time.Duration) {
+	if e == nil {
+		return
+	}
+	select {
+	case <-e.done:
+	case <-time.After(timeout):
+		log.Printf("[ControlPlane] Gave up flushing log records to %s", e.endpoint)
+	}
+}
+
+// stats summarises log export for support bundles; nil when events are not
+// exported.
+func (e *logExporter) stats() map[string]interface{} {
+	if e == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"endpoint": e.endpoint,
+		"exported": e.exported.Load(),
+		"failed":   e.failed.Load(),
+	}
+}
+
+func parseTraceID(s string) (pcommon.TraceID, bool) {
+	var id pcommon.TraceID
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(id) {
+		return id, false
+	}
+	copy(id[:], b)
+	return id, !id.IsEmpty()
+}
+
+func parseSpanID(s string) (pcommon.SpanID, bool) {
+	var id pcommon.SpanID
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(id) {
+		return id, false
+	}
+	copy(id[:], b)
+	return id, !id.IsEmpty()
+}
@@ -2,99 +2,323 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
 	"sync"
+	"syscall"
 	"time"
 
 	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+	jaeger "github.com/Aneesh-Hegde/tracery/controlplane/proto/jaeger"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+const defaultTraceIdleTimeout = 30 * time.Second
+
+// Event types carried in TraceEvent.EventType.
+const (
+	eventTypeSpan                 = "span"
+	eventTypeBreakpointHit        = "breakpoint_hit"
+	eventTypeBreakpointRegistered = "breakpoint_registered"
+	eventTypeBreakpointUpdated    = "breakpoint_updated"
+	eventTypeBreakpointDeleted    = "breakpoint_deleted"
+	eventTypeBreakpointEnabled    = "breakpoint_enabled"
+	eventTypeBreakpointDisabled   = "breakpoint_disabled"
+	eventTypeBreakpointExpired    = "breakpoint_expired"
+	eventTypeEventsDropped        = "events_dropped"
 )
 
 type BreakPoint struct {
 	ID          string
+	Name        string
 	ServiceName string
 	EndPoint    string
 	Conditions  map[string]string
+	Expression  string
 	Enabled     bool
 	CreatedAt   time.Time
+	ExpiresAt   time.Time // zero means the breakpoint never expires
+	HitCount    int64
+	MaxHits     int64 // zero means unlimited
+	DeleteAfterMaxHits bool // delete rather than disable once MaxHits is reached
+	KeepAfterExpiry    bool // disable rather than delete once ExpiresAt passes
+	SingleSpan         bool // match each span alone rather than the service's spans in the trace
+	Source             *pb.SourceLocation // nil when the breakpoint has no source location
+
+	expr *conditionExpr // compiled Expression, nil when there is none
 }
 
 type ControlPlaneServer struct {
 	pb.UnimplementedControlPlaneServer
 	mu            sync.RWMutex
 	breakPoints   map[string]*BreakPoint
-	traceListeners []chan *pb.TraceEvent
+	traceListeners []*subscriber
+	subscriberClasses map[string]*subscriberClass
+	traceAliases  map[string]string
+	aliasAttributes []aliasAttribute // span attributes that become trace aliases
+	fanout        *fanout // nil when broadcast delivers inline
+	alertWebhookToken string // bearer token Alertmanager must send, empty disables the webhook
+	samplingRules map[string]*SamplingRule
+	samplingVersion int64
+	endpointRewrites []*endpointRewrite
+	snapshots     SnapshotStore
+	analytics     *hitAnalytics
+	traces        *traceStore
+	breakpointStore BreakpointStore
+	breakpointWrites int64 // store writes so far, so a reload can tell one raced it
+	leader        *leaderElector // nil when running without a shared store
+	forwarder     *otlpForwarder // nil when spans are not forwarded downstream
+	logExporter   *logExporter   // nil when events are not exported as logs
+	errors        *ErrorLog
+	requests      *RequestLogger
+	startedAt     time.Time
+	draining      bool
+	lastSpanAt    time.Time // when OTLP last delivered a span
+	breakpointSyncErr error // last breakpoint reload failure, nil once it succeeds
+	failingChecks map[string]string // failing self-check name to its problem
+	codeIndex     map[string]*serviceCode // by service name
+	hits          pendingHits // hits not yet written to breakpointStore
+	selfCheckDropped int64 // subscriber drops seen by the last self-check
+	memoryBudget  int64 // TRACERY_MEMORY_BUDGET, zero when unbounded
+	eventBudget   int64 // share of memoryBudget for queued events
+	eventSize     int64 // running average of event sizes, for the event budget
+	eventsShed    int64 // queued events dropped to stay within eventBudget
 }
 
-func NewControlPlaneServer() *ControlPlaneServer {
+func NewControlPlaneServer(snapshots SnapshotStore, breakpoints BreakpointStore) *ControlPlaneServer {
 	return &ControlPlaneServer{
 		breakPoints:   make(map[string]*BreakPoint),
-		traceListeners: make([]chan *pb.TraceEvent, 0),
+		traceListeners: make([]*subscriber, 0),
+		subscriberClasses: defaultSubscriberClasses(),
+		traceAliases:  make(map[string]string),
+		samplingRules: make(map[string]*SamplingRule),
+		endpointRewrites: newEndpointRewrites(),
+		snapshots:     snapshots,
+		analytics:     newHitAnalytics(),
+		traces:        newTraceStore(),
+		breakpointStore: breakpoints,
+		errors:        NewErrorLog(),
+		requests:      NewRequestLogger(),
+		startedAt:     time.Now(),
+		failingChecks: make(map[string]string),
+		codeIndex:     make(map[string]*serviceCode),
 	}
 }
 
 func (s *ControlPlaneServer) RegisterBreakpoint(ctx context.Context, req *pb.RegisterBreakPointRequest) (*pb.RegisterBreakPointResponse, error) {
+	var expr *conditionExpr
+	if req.GetExpression() != "" {
+		var err error
+		expr, err = compileCondition(req.GetExpression())
+		if errors.Is(err, errConditionTooComplex) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid expression: %v", err)
+		}
+		if err != nil {
+			return &pb.RegisterBreakPointResponse{
+				Success:     false,
+				RespMessage: fmt.Sprintf("invalid expression: %v", err),
+			}, nil
+		}
+	}
+
+	if req.GetMaxHits() < 0 {
+		return &pb.RegisterBreakPointResponse{
+			Success:     false,
+			RespMessage: "max_hits must not be negative",
+		}, nil
+	}
+	if req.GetTtlSeconds() < 0 {
+		return &pb.RegisterBreakPointResponse{
+			Success:     false,
+			RespMessage: "ttl_seconds must not be negative",
+		}, nil
+	}
+	var expiresAt time.Time
+	if req.GetTtlSeconds() > 0 {
+		expiresAt = time.Now().Add(time.Duration(req.GetTtlSeconds()) * time.Second)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	source, err := s.resolveSource(req.GetServiceName(), req.GetSource())
+	if err != nil {
+		return &pb.RegisterBreakPointResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("invalid source location: %v", err),
+		}, nil
+	}
+
+	// Spans are matched on their normalized endpoint, so a breakpoint on a
+	// concrete path such as /orders/12345 has to be normalized the same way
+	// or it would never match.
+	endpoint := s.normalizeEndpoint(req.GetEndpoint())
+	endpointNote := ""
+	if endpoint != req.GetEndpoint() {
+		endpointNote = fmt.Sprintf(" (normalized from %s)", req.GetEndpoint())
+	}
+
+	// A known name updates that breakpoint in place so scripts can re-apply
+	// their definitions without piling up duplicates.
+	if req.GetName() != "" {
+		if existing := s.breakpointByName(req.GetName()); existing != nil {
+			updated := *existing
+			updated.ServiceName = req.GetServiceName()
+			updated.EndPoint = endpoint
+			updated.Conditions = req.GetConditions()
+			updated.Expression = req.GetExpression()
+			updated.expr = expr
+			updated.MaxHits = req.GetMaxHits()
+			updated.DeleteAfterMaxHits = req.GetDeleteAfterMaxHits()
+			updated.ExpiresAt = expiresAt
+			updated.KeepAfterExpiry = req.GetKeepAfterExpiry()
+			updated.SingleSpan = req.GetSingleSpan()
+			updated.Source = source
+			if err := s.persistBreakpoint(&updated); err != nil {
+				log.Printf("[ControlPlane] Failed to persist breakpoint %s: %v", existing.ID, err)
+				return &pb.RegisterBreakPointResponse{
+					Success:     false,
+					RespMessage: fmt.Sprintf("failed to persist breakpoint: %v", err),
+				}, nil
+			}
+			*existing = updated
+
+			log.Printf("[ControlPlane] Updated breakpoint %s (%s) for %s%s with the conditions: %v", existing.ID, existing.Name, req.GetServiceName(), endpoint, req.GetConditions())
+			s.broadcast(breakpointEvent(eventTypeBreakpointUpdated, existing))
+
+			return &pb.RegisterBreakPointResponse{
+				BreakpointId: existing.ID,
+				Success:      true,
+				RespMessage:  fmt.Sprintf("Breakpoint %s updated to %s%s", existing.Name, req.GetServiceName(), endpoint) + endpointNote + sourceNote(req.GetServiceName(), source),
+				Source:       source,
+			}, nil
+		}
+	}
+
 	bpID := uuid.New().String()
 
 	breakpoint := &BreakPoint{
 		ID:          bpID,
+		Name:        req.GetName(),
 		ServiceName: req.GetServiceName(),
-		EndPoint:    req.GetEndpoint(),
+		EndPoint:    endpoint,
+		Conditions:  req.GetConditions(),
+		Expression:  req.GetExpression(),
 		Enabled:     true,
 		CreatedAt:   time.Now(),
+		MaxHits:     req.GetMaxHits(),
+		DeleteAfterMaxHits: req.GetDeleteAfterMaxHits(),
+		ExpiresAt:   expiresAt,
+		KeepAfterExpiry:    req.GetKeepAfterExpiry(),
+		SingleSpan:         req.GetSingleSpan(),
+		Source:             source,
+		expr:        expr,
 	}
 
+	if err := s.persistBreakpoint(breakpoint); err != nil {
+		log.Printf("[ControlPlane] Failed to persist breakpoint %s: %v", bpID, err)
+		return &pb.RegisterBreakPointResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("failed to persist breakpoint: %v", err),
+		}, nil
+	}
 	s.breakPoints[bpID] = breakpoint
 
-	log.Printf("[ControlPlane] Registered breakpoint %s for %s%s with the conditions: %v", bpID, req.GetServiceName(), req.GetEndpoint(), req.GetConditions())
+	log.Printf("[ControlPlane] Registered breakpoint %s for %s%s with the conditions: %v", bpID, req.GetServiceName(), endpoint, req.GetConditions())
+	s.broadcast(breakpointEvent(eventTypeBreakpointRegistered, breakpoint))
 
 	return &pb.RegisterBreakPointResponse{
 		BreakpointId: bpID,
 		Success:      true,
-		RespMessage:  fmt.Sprintf("Breakpoint registered at %s%s", req.GetServiceName(), req.GetEndpoint()),
+		RespMessage:  fmt.Sprintf("Breakpoint registered at %s%s", req.GetServiceName(), endpoint) + endpointNote + sourceNote(req.GetServiceName(), source),
+		Source:       source,
 	}, nil
 }
 
+// lookupBreakpoint finds a breakpoint by ID or, failing that, by name.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) lookupBreakpoint(idOrName string) *BreakPoint {
+	if bp, ok := s.breakPoints[idOrName]; ok {
+		return bp
+	}
+	return s.breakpointByName(idOrName)
+}
+
+// breakpointByName returns the breakpoint with the given name, if any.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) breakpointByName(name string) *BreakPoint {
+	if name == "" {
+		return nil
+	}
+	for _, bp := range s.breakPoints {
+		if bp.Name == name {
+			return bp
+		}
+	}
+	return nil
+}
+
 func (s *ControlPlaneServer) ListBreakpoints(ctx context.Context, req *pb.ListBreakpointsRequest) (*pb.ListBreakpointsResponse, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	breakpoints := make([]*pb.Breakpoint, 0, len(s.breakPoints))
 	for _, bp := range s.breakPoints {
-		breakpoints = append(breakpoints, &pb.Breakpoint{
-			Id:          bp.ID,
-			ServiceName: bp.ServiceName,
-			Endpoint:    bp.EndPoint,
-			Conditions:  bp.Conditions,
-			Enabled:     bp.Enabled,
-			CreatedAt:   bp.CreatedAt.Unix(),
-		})
+		breakpoints = append(breakpoints, bp.proto())
 	}
 	return &pb.ListBreakpointsResponse{
 		Breakpoints: breakpoints,
 	}, nil
 }
 
+func (bp *BreakPoint) proto() *pb.Breakpoint {
+	return &pb.Breakpoint{
+		Id:          bp.ID,
+		Name:        bp.Name,
+		ServiceName: bp.ServiceName,
+		Endpoint:    bp.EndPoint,
+		Conditions:  bp.Conditions,
+		Expression:  bp.Expression,
+		Enabled:     bp.Enabled,
+		CreatedAt:   bp.CreatedAt.Unix(),
+		ExpiresAt:   unixOrZero(bp.ExpiresAt),
+		HitCount:    bp.HitCount,
+		MaxHits:     bp.MaxHits,
+		SingleSpan:  bp.SingleSpan,
+		Source:      bp.Source,
+	}
+}
+
 func (s *ControlPlaneServer) DeleteBreakPoint(ctx context.Context, req *pb.DeleteBreakPointRequest) (*pb.DeleteBreakPointResponse, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.breakPoints[req.BreakpointId]; !exists {
+	bp := s.lookupBreakpoint(req.GetBreakpointId())
+	if bp == nil {
 		return &pb.DeleteBreakPointResponse{
 			Success:     false,
 			RespMessage: "Breakpoint not found",
 		}, nil
 	}
 
-	delete(s.breakPoints, req.GetBreakpointId())
+	if err := s.removeBreakpoint(bp); err != nil {
+		return &pb.DeleteBreakPointResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("failed to delete stored breakpoint: %v", err),
+		}, nil
+	}
 	return &pb.DeleteBreakPointResponse{
 		Success:     true,
 		RespMessage: "Breakpoint deleted",
@@ -102,55 +326,484 @@ func (s *ControlPlaneServer) DeleteBreakPoint(ctx context.Context, req *pb.Delet
 
 }
 
-// func (s* ControlPlaneServer) GetSnapshot(ctx context.Context,req *pb.GetSnapshotRequest) (*pb.GetSnapshotResponse,error){
-// 	s.mu.Lock()
-// 	defer s.mu.Unlock()
-// Implementation in phase4
-// }
+// removeBreakpoint deletes bp from the store and memory and announces it.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) removeBreakpoint(bp *BreakPoint) error {
+	if err := s.unpersistBreakpoint(bp); err != nil {
+		log.Printf("[ControlPlane] Failed to delete stored breakpoint %s: %v", bp.ID, err)
+		return err
+	}
+	delete(s.breakPoints, bp.ID)
+	s.broadcast(breakpointEvent(eventTypeBreakpointDeleted, bp))
+	return nil
+}
+
+// SetBreakpointEnabled mutes or unmutes a breakpoint without losing its
+// definition. Disabled breakpoints never match in CheckBreakpoint.
+func (s *ControlPlaneServer) SetBreakpointEnabled(ctx context.Context, req *pb.SetBreakpointEnabledRequest) (*pb.SetBreakpointEnabledResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bp := s.lookupBreakpoint(req.GetBreakpointId())
+	if bp == nil {
+		return &pb.SetBreakpointEnabledResponse{
+			Success:     false,
+			RespMessage: "Breakpoint not found",
+		}, nil
+	}
+
+	state := "disabled"
+	if req.GetEnabled() {
+		state = "enabled"
+	}
+	if bp.Enabled == req.GetEnabled() {
+		return &pb.SetBreakpointEnabledResponse{
+			Success:     true,
+			RespMessage: fmt.Sprintf("Breakpoint already %s", state),
+		}, nil
+	}
+
+	if err := s.setBreakpointEnabled(bp, req.GetEnabled()); err != nil {
+		return &pb.SetBreakpointEnabledResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("failed to persist breakpoint: %v", err),
+		}, nil
+	}
+
+	return &pb.SetBreakpointEnabledResponse{
+		Success:     true,
+		RespMessage: fmt.Sprintf("Breakpoint %s", state),
+	}, nil
+}
+
+// setBreakpointEnabled persists and announces a change of bp's state.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) setBreakpointEnabled(bp *BreakPoint, enabled bool) error {
+	state, eventType := "disabled", eventTypeBreakpointDisabled
+	if enabled {
+		state, eventType = "enabled", eventTypeBreakpointEnabled
+	}
+
+	updated := *bp
+	updated.Enabled = enabled
+	if updated.Enabled {
+		// Re-enabling a breakpoint that used up its hits re-arms it.
+		updated.HitCount = 0
+	}
+	if err := s.persistBreakpoint(&updated); err != nil {
+		log.Printf("[ControlPlane] Failed to persist breakpoint %s: %v", bp.ID, err)
+		return err
+	}
+	*bp = updated
+	log.Printf("[ControlPlane] Breakpoint %s %s", bp.ID, state)
+	s.broadcast(breakpointEvent(eventType, bp))
+	return nil
+}
 
 func (s *ControlPlaneServer) StreamTraces (req *pb.StreamTracesRequest, stream pb.ControlPlane_StreamTracesServer) (error){
-	ch:=make(chan *pb.TraceEvent,100)
+	sub, unsubscribe, err := s.subscribe(req.GetSubscriberClass(), "", req.GetEventTypes())
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	for event:=range sub.ch {
+		if err:=stream.Send(event); err!=nil{
+			return err
+		}
+	}
+
+	if sub.err != nil {
+		return sub.err
+	}
+	return nil
+
+}
+
+// StreamTrace follows a single trace. The stream ends once no event for the
+// trace has been seen for the idle timeout, which is how we treat the trace
+// as finished. If the client falls behind and events are dropped, it is
+// told how many with an events_dropped event.
+func (s *ControlPlaneServer) StreamTrace(req *pb.StreamTraceRequest, stream pb.ControlPlane_StreamTraceServer) error {
+	if req.GetTraceId() == "" {
+		return status.Error(codes.InvalidArgument, "trace_id is required")
+	}
+
+	idleTimeout := defaultTraceIdleTimeout
+	if req.GetIdleTimeoutSeconds() > 0 {
+		idleTimeout = time.Duration(req.GetIdleTimeoutSeconds()) * time.Second
+	}
+
+	traceID := s.resolveTraceID(req.GetTraceId())
+
+	sub, unsubscribe, err := s.subscribe(defaultSubscriberClass, traceID, nil)
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	idle := time.NewTimer(idleTimeout)
+	defer idle.Stop()
+
+	reported := int64(0)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-idle.C:
+			return nil
+		case event, ok := <-sub.ch:
+			if !ok {
+				if sub.err != nil {
+					return sub.err
+				}
+				return nil
+			}
+			if dropped := sub.dropped.Load(); dropped > reported {
+				if err := stream.Send(droppedEvent(traceID, dropped-reported)); err != nil {
+					return err
+				}
+				reported = dropped
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+			idle.Reset(idleTimeout)
+		}
+	}
+}
+
+// RegisterTraceAlias maps a business correlation key (such as an order ID) to
+// a trace ID so it can be used anywhere a trace ID is accepted. Aliases are
+// also registered from span attributes named in TRACERY_ALIAS_ATTRIBUTES.
+func (s *ControlPlaneServer) RegisterTraceAlias(ctx context.Context, req *pb.RegisterTraceAliasRequest) (*pb.RegisterTraceAliasResponse, error) {
+	if req.GetAlias() == "" || req.GetTraceId() == "" {
+		return &pb.RegisterTraceAliasResponse{
+			Success:     false,
+			RespMessage: "alias and trace_id are required",
+		}, nil
+	}
 
 	s.mu.Lock()
-	s.traceListeners=append(s.traceListeners,ch)
+	s.traceAliases[req.GetAlias()] = req.GetTraceId()
 	s.mu.Unlock()
 
-	defer func(){
-		s.mu.Lock()
-		for i, listener:=range s.traceListeners{
-			if listener==ch{
-				s.traceListeners= append(s.traceListeners[:i],s.traceListeners[i+1:]...)
-				break
+	log.Printf("[ControlPlane] Registered alias %s for trace %s", req.GetAlias(), req.GetTraceId())
+
+	return &pb.RegisterTraceAliasResponse{
+		Success:     true,
+		RespMessage: fmt.Sprintf("%s now refers to trace %s", req.GetAlias(), req.GetTraceId()),
+	}, nil
+}
+
+// resolveTraceID returns the trace ID an alias points to, or the input
+// unchanged when it is not a known alias. It takes s.mu itself, so callers
+// must not hold it.
+func (s *ControlPlaneServer) resolveTraceID(idOrAlias string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if traceID, ok := s.traceAliases[idOrAlias]; ok {
+		return traceID
+	}
+	return idOrAlias
+}
+
+// reapExpiredBreakpoints retires every breakpoint whose TTL has passed, so a
+// forgotten breakpoint can't keep matching production traffic days later.
+// Expired breakpoints are deleted unless they asked to be kept, in which
+// case they are disabled and lose their expiry.
+func (s *ControlPlaneServer) reapExpiredBreakpoints(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, bp := range s.breakPoints {
+		if bp.ExpiresAt.IsZero() || now.Before(bp.ExpiresAt) {
+			continue
+		}
+		// On a store failure the breakpoint is left as it was and the pass
+		// stops, so a down database holds s.mu for one timeout rather than
+		// one per expired breakpoint. The next pass tries again.
+		if bp.KeepAfterExpiry {
+			updated := *bp
+			updated.Enabled = false
+			updated.ExpiresAt = time.Time{}
+			if err := s.persistBreakpoint(&updated); err != nil {
+				log.Printf("[ControlPlane] Failed to persist breakpoint %s: %v", id, err)
+				return
+			}
+			*bp = updated
+			log.Printf("[ControlPlane] Breakpoint %s expired and was disabled", id)
+		} else {
+			if err := s.unpersistBreakpoint(bp); err != nil {
+				log.Printf("[ControlPlane] Failed to delete stored breakpoint %s: %v", id, err)
+				return
 			}
+			delete(s.breakPoints, id)
+			log.Printf("[ControlPlane] Breakpoint %s expired and was deleted", id)
 		}
-		s.mu.Unlock()
-		close(ch)
-	}()
+		s.broadcast(breakpointEvent(eventTypeBreakpointExpired, bp))
+	}
+}
 
-	for event:=range ch {
-		if err:=stream.Send(event); err!=nil{
-			return err
+func (s *ControlPlaneServer) runExpiryReaper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		// Only one replica retires breakpoints, so expiry events aren't
+		// published once per replica.
+		if !s.isLeader() {
+			continue
 		}
+		s.reapExpiredBreakpoints(now)
 	}
+}
 
-	return nil
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// broadcast fans an event out to every trace listener that wants it. A full
+// listener is handled by its class's drop policy rather than stalling the
+// caller. With TRACERY_BROADCAST_WORKERS set, delivery is split across the
+// fanout workers. Callers must hold s.mu.
+func (s *ControlPlaneServer) broadcast(event *pb.TraceEvent) {
+	keep := s.fanout.deliver(event, s.traceListeners)
+	kept := s.traceListeners[:0]
+	for i, sub := range s.traceListeners {
+		if keep[i] {
+			kept = append(kept, sub)
+			continue
+		}
+		log.Printf("[ControlPlane] Disconnecting %s subscriber that fell behind", sub.class.Name)
+		sub.err = laggedError()
+		close(sub.ch)
+	}
+	for i := len(kept); i < len(s.traceListeners); i++ {
+		s.traceListeners[i] = nil
+	}
+	s.traceListeners = kept
+	s.shedEvents(event)
+}
+
+// droppedEvent tells a single-trace stream that n events for the trace were
+// dropped because it fell behind.
+func droppedEvent(traceID string, n int64) *pb.TraceEvent {
+	return &pb.TraceEvent{
+		TraceId:    traceID,
+		Timestamp:  time.Now().Unix(),
+		Attributes: map[string]string{"dropped": strconv.FormatInt(n, 10)},
+		EventType:  eventTypeEventsDropped,
+	}
+}
+
+func breakpointEvent(eventType string, bp *BreakPoint) *pb.TraceEvent {
+	return &pb.TraceEvent{
+		ServiceName:  bp.ServiceName,
+		Endpoint:     bp.EndPoint,
+		Timestamp:    time.Now().Unix(),
+		Attributes:   bp.Conditions,
+		EventType:    eventType,
+		BreakpointId: bp.ID,
+	}
+}
+
+// subscribe registers a new trace listener of the given class, optionally
+// limited to one trace and to some event types. The returned function
+// removes the listener and closes its channel unless broadcast already
+// disconnected it.
+func (s *ControlPlaneServer) subscribe(className, traceID string, eventTypes []string) (*subscriber, func(), error) {
+	if className == "" {
+		className = defaultSubscriberClass
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	if s.draining {
+		return nil, nil, status.Errorf(codes.Unavailable, "control plane is shutting down; reconnect to %s", reconnectHint())
+	}
+
+	class, ok := s.subscriberClasses[className]
+	if !ok {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "unknown subscriber class %q", className)
+	}
+
+	sub := &subscriber{
+		ch:         make(chan *pb.TraceEvent, class.BufferSize),
+		class:      class,
+		traceID:    traceID,
+		eventTypes: make(map[string]bool, len(eventTypes)),
+	}
+	for _, t := range eventTypes {
+		sub.eventTypes[t] = true
+	}
+	s.traceListeners = append(s.traceListeners, sub)
+
+	return sub, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, listener := range s.traceListeners {
+			if listener == sub {
+				s.traceListeners = append(s.traceListeners[:i], s.traceListeners[i+1:]...)
+				close(sub.ch)
+				break
+			}
+		}
+	}, nil
 }
 
 func main(){
 	listener,err:=net.Listen("tcp",":50051")
 	if err!=nil{
-		log.Fatal("Failed to listen: %v",err)
+		log.Fatalf("Failed to listen: %v",err)
 	}
 
-	grpcServer:=grpc.NewServer()
-	controlplane:=NewControlPlaneServer()
+	snapshots,err:=newSnapshotStoreFromEnv()
+	if err!=nil{
+		log.Fatalf("Failed to open snapshot store: %v",err)
+	}
+
+	breakpoints,err:=newBreakpointStoreFromEnv()
+	if err!=nil{
+		log.Fatalf("Failed to open breakpoint store: %v",err)
+	}
+
+	controlplane:=NewControlPlaneServer(snapshots,breakpoints)
+	// The elector must be in place before the reaper and other background
+	// work start asking isLeader.
+	if store,ok:=breakpoints.(*postgresBreakpointStore);ok{
+		controlplane.leader=newLeaderElector(store.db)
+	}
+	budget,err:=memoryBudgetFromEnv()
+	if err!=nil{
+		log.Fatalf("Failed to read memory budget: %v",err)
+	}
+	controlplane.setMemoryBudget(budget)
+	controlplane.aliasAttributes,err=aliasAttributesFromEnv()
+	if err!=nil{
+		log.Fatalf("Failed to read alias attributes: %v",err)
+	}
+	controlplane.alertWebhookToken=os.Getenv("TRACERY_ALERT_WEBHOOK_TOKEN")
+	workers,err:=broadcastWorkersFromEnv()
+	if err!=nil{
+		log.Fatalf("Failed to read broadcast workers: %v",err)
+	}
+	controlplane.fanout=newFanout(workers)
+	if err:=controlplane.loadBreakpoints();err!=nil{
+		log.Fatalf("Failed to load breakpoints: %v",err)
+	}
+	controlplane.forwarder,err=newOTLPForwarderFromEnv()
+	if err!=nil{
+		log.Fatalf("Failed to set up OTLP forwarding: %v",err)
+	}
+	controlplane.logExporter,err=controlplane.startLogExporterFromEnv()
+	if err!=nil{
+		log.Fatalf("Failed to set up OTLP log export: %v",err)
+	}
+	limiter:=NewRateLimiter()
+	grpcServer:=grpc.NewServer(
+		grpc.ChainUnaryInterceptor(controlplane.requests.UnaryInterceptor, controlplane.errors.UnaryInterceptor, limiter.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(controlplane.requests.StreamInterceptor, controlplane.errors.StreamInterceptor, limiter.StreamInterceptor),
+	)
 
 	pb.RegisterControlPlaneServer(grpcServer,controlplane)
 	reflection.Register(grpcServer)
 
+	retention,err:=snapshotRetentionFromEnv()
+	if err!=nil{
+		log.Fatalf("Failed to read snapshot retention: %v",err)
+	}
+	if retention.enabled(){
+		go controlplane.runSnapshotGC(retention,snapshotGCInterval)
+	}
+
+	go controlplane.runExpiryReaper(10*time.Second)
+	go controlplane.runSelfChecks(defaultSelfChecks(),selfCheckInterval)
+	if breakpoints!=nil{
+		go controlplane.runBreakpointSync(breakpointSyncInterval)
+		go controlplane.runHitWriter(hitWriteInterval)
+	}
+	if controlplane.leader!=nil{
+		go controlplane.leader.run(leaderElectionInterval)
+	}
+
+	observerListener,err:=net.Listen("tcp",":50052")
+	if err!=nil{
+		log.Fatalf("Failed to listen for observers: %v",err)
+	}
+	// Dashboards get their own budget so they can't use up the streams
+	// people debugging need.
+	observerLimiter:=NewRateLimiter()
+	observerServer:=grpc.NewServer(
+		grpc.ChainUnaryInterceptor(controlplane.requests.UnaryInterceptor, controlplane.errors.UnaryInterceptor, observerLimiter.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(controlplane.requests.StreamInterceptor, controlplane.errors.StreamInterceptor, observerLimiter.StreamInterceptor),
+	)
+	pb.RegisterObserverServer(observerServer,NewObserverServer(controlplane))
+	reflection.Register(observerServer)
+
+	go func(){
+		if err:=observerServer.Serve(observerListener);err!=nil{
+			log.Fatalf("Failed to serve observers: %v",err)
+		}
+	}()
+
+	otlpListener,err:=net.Listen("tcp",":4317")
+	if err!=nil{
+		log.Fatalf("Failed to listen for OTLP: %v",err)
+	}
+	otlpServer:=grpc.NewServer()
+	ptraceotlp.RegisterGRPCServer(otlpServer,newOTLPReceiver(controlplane))
+
+	go func(){
+		if err:=otlpServer.Serve(otlpListener);err!=nil{
+			log.Fatalf("Failed to serve OTLP: %v",err)
+		}
+	}()
+
+	jaegerListener,err:=net.Listen("tcp",":14250")
+	if err!=nil{
+		log.Fatalf("Failed to listen for Jaeger: %v",err)
+	}
+	jaegerServer:=grpc.NewServer()
+	jaeger.RegisterCollectorServiceServer(jaegerServer,newJaegerReceiver(controlplane))
+
+	go func(){
+		if err:=jaegerServer.Serve(jaegerListener);err!=nil{
+			log.Fatalf("Failed to serve Jaeger: %v",err)
+		}
+	}()
+
+	go func(){
+		if err:=http.ListenAndServe(":8080",controlplane.httpHandler());err!=nil{
+			log.Fatalf("Failed to serve HTTP: %v",err)
+		}
+	}()
+
+	go func(){
+		sigs:=make(chan os.Signal,1)
+		signal.Notify(sigs,syscall.SIGTERM,syscall.SIGINT)
+		<-sigs
+
+		controlplane.drain(reconnectHint())
+		time.Sleep(drainGracePeriod)
+
+		log.Printf("[ControlPlane] Stopping servers")
+		observerServer.GracefulStop()
+		otlpServer.GracefulStop()
+		jaegerServer.GracefulStop()
+		controlplane.forwarder.stop(forwardTimeout)
+		controlplane.logExporter.wait(forwardTimeout)
+		grpcServer.GracefulStop()
+	}()
+
 	if err:=grpcServer.Serve(listener);err!=nil{
-		log.Fatal("Failed to serve: %v",err)
+		log.Fatalf("Failed to serve: %v",err)
 	}
 
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryEntryOverhead is a rough per-entry cost of maps, slices and struct
+// headers, added to string lengths when estimating sizes.
+const memoryEntryOverhead = 64
+
+// How TRACERY_MEMORY_BUDGET is split between the things that grow with
+// traffic. Traces get the most since they are what breakpoints are matched
+// against; event queues only need to absorb bursts.
+const (
+	traceBudgetShare    = 0.6
+	snapshotBudgetShare = 0.25
+	eventBudgetShare    = 0.15
+)
+
+// memoryBudgetFromEnv reads TRACERY_MEMORY_BUDGET, e.g. 512Mi or 2G. Zero
+// means unbounded: only the fixed count limits apply.
+func memoryBudgetFromEnv() (int64, error) {
+	v := os.Getenv("TRACERY_MEMORY_BUDGET")
+	if v == "" {
+		return 0, nil
+	}
+	budget, err := parseByteSize(v)
+	if err != nil {
+		return 0, fmt.Errorf("TRACERY_MEMORY_BUDGET: %v", err)
+	}
+	return budget, nil
+}
+
+// parseByteSize reads a byte count with an optional K, M or G suffix
+// (powers of 1000) or Ki, Mi or Gi (powers of 1024), as Kubernetes does. A
+// trailing B is allowed.
+func parseByteSize(s string) (int64, error) {
+	num := strings.TrimSuffix(strings.TrimSpace(s), "B")
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		factor int64
+	}{
+		{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30},
+		{"K", 1e3}, {"M", 1e6}, {"G", 1e9},
+	} {
+		if strings.HasSuffix(num, unit.suffix) {
+			num = strings.TrimSuffix(num, unit.suffix)
+			multiplier = unit.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * multiplier, nil
+}
+
+// setMemoryBudget bounds the trace store, in-memory snapshots and event
+// queues by their share of budget.
+func (s *ControlPlaneServer) setMemoryBudget(budget int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memoryBudget = budget
+	s.traces.budget = int64(float64(budget) * traceBudgetShare)
+	s.eventBudget = int64(float64(budget) * eventBudgetShare)
+	if store := memorySnapshots(s.snapshots); store != nil {
+		store.setBudget(int64(float64(budget) * snapshotBudgetShare))
+	}
+	if budget > 0 {
+		log.Printf("[ControlPlane] Memory budget %d bytes: %d for traces, %d for events", budget, s.traces.budget, s.eventBudget)
+	}
+}
+
+// memorySnapshots returns the in-memory store holding snapshots, if any.
+// Snapshots on disk or in a blob store do not count against the budget.
+func memorySnapshots(store SnapshotStore) *memorySnapshotStore {
+	switch store := store.(type) {
+	case *memorySnapshotStore:
+		return store
+	case *blobSnapshotStore:
+		return memorySnapshots(store.meta)
+	}
+	return nil
+}
+
+// shedEvents drops the oldest queued events, from the longest queues
+// first, until the queues fit the event budget. Queue memory is estimated
+// from a running average of event sizes. Callers must hold s.mu.
+func (s *ControlPlaneServer) shedEvents(event *pb.TraceEvent) {
+	if s.eventBudget <= 0 {
+		return
+	}
+	size := int64(proto.Size(event)) + memoryEntryOverhead
+	if s.eventSize == 0 {
+		s.eventSize = size
+	} else {
+		s.eventSize = (7*s.eventSize + size) / 8
+	}
+
+	queued := int64(0)
+	for _, sub := range s.traceListeners {
+		queued += int64(len(sub.ch))
+	}
+	for queued*s.eventSize > s.eventBudget {
+		var longest *subscriber
+		for _, sub := range s.traceListeners {
+			if longest == nil || len(sub.ch) > len(longest.ch) {
+				longest = sub
+			}
+		}
+		if longest == nil {
+			return
+		}
+		select {
+		case <-longest.ch:
+		default:
+			return
+		}
+		queued--
+		longest.class.Dropped.Add(1)
+		s.eventsShed++
+	}
+}
+
+// storageStats reports estimated memory use against the budget. Callers
+// must hold s.mu.
+func (s *ControlPlaneServer) storageStats() []*pb.StorageComponent {
+	queued := int64(0)
+	for _, sub := range s.traceListeners {
+		queued += int64(len(sub.ch))
+	}
+	components := []*pb.StorageComponent{
+		{
+			Name:        "traces",
+			UsedBytes:   s.traces.bytes,
+			BudgetBytes: s.traces.budget,
+			Items:       int64(len(s.traces.order)),
+			Shed:        s.traces.shed,
+		},
+		{
+			Name:        "events",
+			UsedBytes:   queued * s.eventSize,
+			BudgetBytes: s.eventBudget,
+			Items:       queued,
+			Shed:        s.eventsShed,
+		},
+	}
+	if store := memorySnapshots(s.snapshots); store != nil {
+		c := &pb.StorageComponent{Name: "snapshots"}
+		c.UsedBytes, c.BudgetBytes, c.Items, c.Shed = store.usage()
+		components = append(components, c)
+	}
+	return components
+}
+
+// GetStorageStats reports how much memory the trace store, snapshots and
+// event queues hold against the memory budget, and how much each has shed.
+func (s *ControlPlaneServer) GetStorageStats(ctx context.Context, req *pb.GetStorageStatsRequest) (*pb.GetStorageStatsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := &pb.GetStorageStatsResponse{
+		BudgetBytes: s.memoryBudget,
+		Components:  s.storageStats(),
+	}
+	for _, c := range resp.Components {
+		resp.UsedBytes += c.UsedBytes
+	}
+	return resp, nil
+}
+
+// handleMetrics serves storage stats in the Prometheus text format.
+func (s *ControlPlaneServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	budget := s.memoryBudget
+	components := s.storageStats()
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# HELP tracery_memory_budget_bytes Memory budget, 0 when unbounded.\n")
+	fmt.Fprintf(w, "# TYPE tracery_memory_budget_bytes gauge\n")
+	fmt.Fprintf(w, "tracery_memory_budget_bytes %d\n", budget)
+	for _, metric := range []struct {
+		name, kind, help string
+		value            func(*pb.StorageComponent) int64
+	}{
+		{"tracery_storage_used_bytes", "gauge", "Estimated memory held by each store.", (*pb.StorageComponent).GetUsedBytes},
+		{"tracery_storage_budget_bytes", "gauge", "Each store's share of the memory budget, 0 when unbounded.", (*pb.StorageComponent).GetBudgetBytes},
+		{"tracery_storage_items", "gauge", "Traces, snapshots or queued events held by each store.", (*pb.StorageComponent).GetItems},
+		{"tracery_storage_shed_total", "counter", "Items dropped to stay within the memory budget.", (*pb.StorageComponent).GetShed},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", metric.name, metric.kind)
+		for _, c := range components {
+			fmt.Fprintf(w, "%s{component=%q} %d\n", metric.name, c.GetName(), metric.value(c))
+		}
+	}
+}
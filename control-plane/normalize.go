@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+)
+
+// endpointRewrite turns concrete paths into the route templates people set
+// breakpoints on, e.g. /orders/12345 -> /orders/{id}.
+type endpointRewrite struct {
+	Pattern     string
+	Replacement string
+	re          *regexp.Regexp
+}
+
+// defaultEndpointRewrites collapse the ID segments we see in practice. UUIDs
+// go first so their leading digits aren't taken for a numeric ID.
+var defaultEndpointRewrites = [][2]string{
+	{`/[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`, "/{uuid}"},
+	{`/[0-9]+\b`, "/{id}"},
+}
+
+func newEndpointRewrites() []*endpointRewrite {
+	rewrites := make([]*endpointRewrite, 0, len(defaultEndpointRewrites))
+	for _, r := range defaultEndpointRewrites {
+		rewrites = append(rewrites, &endpointRewrite{
+			Pattern:     r[0],
+			Replacement: r[1],
+			re:          regexp.MustCompile(r[0]),
+		})
+	}
+	return rewrites
+}
+
+// normalizeEndpoint applies every rewrite in order. Breakpoint endpoints are
+// matched against the result, so /orders/{id} catches every order.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) normalizeEndpoint(endpoint string) string {
+	for _, r := range s.endpointRewrites {
+		endpoint = r.re.ReplaceAllString(endpoint, r.Replacement)
+	}
+	return endpoint
+}
+
+// SetEndpointRewrite adds a rewrite, or replaces the one with the same
+// pattern. New rewrites run after the existing ones.
+func (s *ControlPlaneServer) SetEndpointRewrite(ctx context.Context, req *pb.SetEndpointRewriteRequest) (*pb.SetEndpointRewriteResponse, error) {
+	re, err := regexp.Compile(req.GetPattern())
+	if err != nil || req.GetPattern() == "" {
+		return &pb.SetEndpointRewriteResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("invalid pattern %q", req.GetPattern()),
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rewrite := &endpointRewrite{Pattern: req.GetPattern(), Replacement: req.GetReplacement(), re: re}
+	replaced := false
+	for i, r := range s.endpointRewrites {
+		if r.Pattern == req.GetPattern() {
+			s.endpointRewrites[i] = rewrite
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.endpointRewrites = append(s.endpointRewrites, rewrite)
+	}
+
+	log.Printf("[ControlPlane] Endpoint rewrite %s -> %s", req.GetPattern(), req.GetReplacement())
+
+	return &pb.SetEndpointRewriteResponse{
+		Success:     true,
+		RespMessage: fmt.Sprintf("Rewriting %s -> %s", req.GetPattern(), req.GetReplacement()),
+	}, nil
+}
+
+func (s *ControlPlaneServer) GetEndpointRewrites(ctx context.Context, req *pb.GetEndpointRewritesRequest) (*pb.GetEndpointRewritesResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := &pb.GetEndpointRewritesResponse{}
+	for _, r := range s.endpointRewrites {
+		resp.Rewrites = append(resp.Rewrites, &pb.EndpointRewrite{
+			Pattern:     r.Pattern,
+			Replacement: r.Replacement,
+		})
+	}
+	if req.GetEndpoint() != "" {
+		resp.Normalized = s.normalizeEndpoint(req.GetEndpoint())
+	}
+	return resp, nil
+}
+
+func (s *ControlPlaneServer) DeleteEndpointRewrite(ctx context.Context, req *pb.DeleteEndpointRewriteRequest) (*pb.DeleteEndpointRewriteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.endpointRewrites {
+		if r.Pattern == req.GetPattern() {
+			s.endpointRewrites = append(s.endpointRewrites[:i], s.endpointRewrites[i+1:]...)
+			return &pb.DeleteEndpointRewriteResponse{
+				Success:     true,
+				RespMessage: "Endpoint rewrite deleted",
+			}, nil
+		}
+	}
+	return &pb.DeleteEndpointRewriteResponse{
+		Success:     false,
+		RespMessage: "Endpoint rewrite not found",
+	}, nil
+}
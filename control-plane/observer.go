@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ObserverServer is the read-only API for dashboards and other teams that
+// want to watch debugging activity. It only exposes the event stream and the
+// breakpoint list, and always redacts attribute and condition values since
+// those can carry customer data, and normalizes span names the way it does
+// endpoints. It runs on its own port so the write APIs can stay restricted
+// to operators.
+type ObserverServer struct {
+	pb.UnimplementedObserverServer
+	cp *ControlPlaneServer
+}
+
+func NewObserverServer(cp *ControlPlaneServer) *ObserverServer {
+	return &ObserverServer{cp: cp}
+}
+
+func (o *ObserverServer) StreamTraces(req *pb.StreamTracesRequest, stream pb.Observer_StreamTracesServer) error {
+	sub, unsubscribe, err := o.cp.subscribe(req.GetSubscriberClass(), "", req.GetEventTypes())
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	for event := range sub.ch {
+		// Events are shared with every listener, so redact a copy.
+		redactedEvent := proto.Clone(event).(*pb.TraceEvent)
+		redactedEvent.Attributes = redactValues(event.Attributes)
+		// The raw endpoint keeps the IDs normalization took out.
+		redactedEvent.RawEndpoint = ""
+		if span := redactedEvent.GetSpan(); span != nil {
+			// Span names are often the request path, IDs and all.
+			o.cp.mu.RLock()
+			span.Name = o.cp.normalizeEndpoint(span.Name)
+			o.cp.mu.RUnlock()
+			if span.StatusMessage != "" {
+				span.StatusMessage = redacted
+			}
+			for _, e := range span.Events {
+				e.Attributes = redactValues(e.Attributes)
+			}
+			for _, l := range span.Links {
+				l.Attributes = redactValues(l.Attributes)
+			}
+		}
+		if err := stream.Send(redactedEvent); err != nil {
+			return err
+		}
+	}
+
+	if sub.err != nil {
+		return sub.err
+	}
+	return nil
+}
+
+func (o *ObserverServer) ListBreakpoints(ctx context.Context, req *pb.ListBreakpointsRequest) (*pb.ListBreakpointsResponse, error) {
+	resp, err := o.cp.ListBreakpoints(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, bp := range resp.Breakpoints {
+		bp.Conditions = redactValues(bp.Conditions)
+		bp.Expression = redactExpression(bp.Expression)
+	}
+	return resp, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/grpc"
+)
+
+type observerStream struct {
+	grpc.ServerStream
+	sent chan *pb.TraceEvent
+}
+
+func (s *observerStream) Context() context.Context { return context.Background() }
+
+func (s *observerStream) Send(event *pb.TraceEvent) error {
+	s.sent <- event
+	return io.EOF
+}
+
+func TestObserverRedactsSpans(t *testing.T) {
+	s := NewControlPlaneServer(nil, nil)
+	stream := &observerStream{sent: make(chan *pb.TraceEvent, 1)}
+	go NewObserverServer(s).StreamTraces(&pb.StreamTracesRequest{}, stream)
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		s.mu.RLock()
+		subscribed := len(s.traceListeners) > 0
+		s.mu.RUnlock()
+		if subscribed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("observer never subscribed")
+		}
+	}
+
+	s.mu.Lock()
+	s.broadcast(&pb.TraceEvent{
+		TraceId:     "trace-a",
+		Endpoint:    "/orders/{id}",
+		RawEndpoint: "/orders/12345",
+		Attributes:  map[string]string{"customer_id": "CUST-123"},
+		EventType:   eventTypeSpan,
+		Span: &pb.Span{
+			Name:          "GET /orders/12345",
+			StatusMessage: "card 4111111111111111 declined",
+		},
+	})
+	s.mu.Unlock()
+
+	event := <-stream.sent
+	if event.RawEndpoint != "" || event.Attributes["customer_id"] == "CUST-123" {
+		t.Errorf("event not redacted: %+v", event)
+	}
+	if got := event.GetSpan().GetName(); got != "GET /orders/{id}" {
+		t.Errorf("span name = %q, want GET /orders/{id}", got)
+	}
+	if event.GetSpan().GetStatusMessage() != redacted {
+		t.Errorf("status message = %q, want it redacted", event.GetSpan().GetStatusMessage())
+	}
+}
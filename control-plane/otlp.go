@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+)
+
+// endpointAttributes are checked in order to find the endpoint a span
+// served; spans without any of them are reported under their span name.
+// http.route comes first because it is already a template; http.path is
+// what Zipkin instrumentation records.
+var endpointAttributes = []string{"http.route", "url.path", "http.target", "http.path", "rpc.method"}
+
+// otlpReceiver accepts OTLP trace exports, so the collector can fan spans
+// out to the control plane alongside Jaeger. Every span is assembled into
+// its trace, published as a span event and checked against the registered
+// breakpoints.
+type otlpReceiver struct {
+	ptraceotlp.UnimplementedGRPCServer
+	cp *ControlPlaneServer
+}
+
+func newOTLPReceiver(cp *ControlPlaneServer) *otlpReceiver {
+	return &otlpReceiver{cp: cp}
+}
+
+func (r *otlpReceiver) Export(ctx context.Context, req ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	r.cp.ingestTraces(req.Traces())
+	return ptraceotlp.NewExportResponse(), nil
+}
+
+// ingestTraces publishes the spans in traces to the event stream, and
+// forwards them downstream when a forwarding endpoint is configured.
+func (s *ControlPlaneServer) ingestTraces(traces ptrace.Traces) {
+	s.forwarder.forward(traces)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSpanAt = time.Now()
+	resourceSpans := traces.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		rs := resourceSpans.At(i)
+		service := "unknown"
+		if v, ok := rs.Resource().Attributes().Get("service.name"); ok {
+			service = v.AsString()
+		}
+
+		scopeSpans := rs.ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				s.indexCode(service, rs.Resource().Attributes(), spans.At(k).Attributes())
+				s.ingestSpan(service, spans.At(k))
+			}
+		}
+	}
+}
+
+// ingestSpan stores and publishes one span and evaluates breakpoints
+// against it.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) ingestSpan(service string, span ptrace.Span) {
+	attrs := spanAttributes(span.Attributes())
+	endpoint := span.Name()
+	for _, key := range endpointAttributes {
+		if v, ok := attrs[key]; ok && v != "" {
+			endpoint = v
+			break
+		}
+	}
+	rawEndpoint := endpoint
+	endpoint = s.normalizeEndpoint(endpoint)
+	if rawEndpoint == endpoint {
+		rawEndpoint = ""
+	}
+	traceID := span.TraceID().String()
+	meta := spanMetadata(span)
+	s.aliasTraceFromAttributes(traceID, attrs)
+
+	s.traces.add(traceID, &storedSpan{
+		ServiceName: service,
+		Endpoint:    endpoint,
+		Attributes:  attrs,
+		Span:        meta,
+	}, time.Now())
+
+	s.broadcast(&pb.TraceEvent{
+		TraceId:     traceID,
+		ServiceName: service,
+		Endpoint:    endpoint,
+		RawEndpoint: rawEndpoint,
+		Timestamp:   span.StartTimestamp().AsTime().Unix(),
+		Attributes:  attrs,
+		EventType:   eventTypeSpan,
+		Span:        meta,
+	})
+
+	s.evaluateBreakpoints(&pb.CheckBreakpointRequest{
+		TraceId:     traceID,
+		ServiceName: service,
+		Endpoint:    endpoint,
+		RawEndpoint: rawEndpoint,
+		Attributes:  attrs,
+	})
+}
+
+// spanMetadata copies what a span says about itself beyond its attributes:
+// where it sits in the trace, when it ran, how it ended and what happened
+// along the way.
+func spanMetadata(span ptrace.Span) *pb.Span {
+	meta := &pb.Span{
+		SpanId:            span.SpanID().String(),
+		ParentSpanId:      span.ParentSpanID().String(),
+		Name:              span.Name(),
+		Kind:              span.Kind().String(),
+		StartTimeUnixNano: int64(span.StartTimestamp()),
+		EndTimeUnixNano:   int64(span.EndTimestamp()),
+		StatusCode:        span.Status().Code().String(),
+		StatusMessage:     span.Status().Message(),
+	}
+
+	events := span.Events()
+	for i := 0; i < events.Len(); i++ {
+		e := events.At(i)
+		meta.Events = append(meta.Events, &pb.SpanEvent{
+			Name:         e.Name(),
+			TimeUnixNano: int64(e.Timestamp()),
+			Attributes:   spanAttributes(e.Attributes()),
+		})
+	}
+
+	links := span.Links()
+	for i := 0; i < links.Len(); i++ {
+		l := links.At(i)
+		meta.Links = append(meta.Links, &pb.SpanLink{
+			TraceId:    l.TraceID().String(),
+			SpanId:     l.SpanID().String(),
+			Attributes: spanAttributes(l.Attributes()),
+		})
+	}
+	return meta
+}
+
+// spanAttributes flattens span attributes to strings, the form breakpoint
+// conditions and expressions work on.
+func spanAttributes(m pcommon.Map) map[string]string {
+	attrs := make(map[string]string, m.Len())
+	m.Range(func(k string, v pcommon.Value) bool {
+		attrs[k] = v.AsString()
+		return true
+	})
+	return attrs
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+)
+
+// wantSpan is what ingestTraces should store for one span of the golden
+// request.
+type wantSpan struct {
+	traceID     string
+	spanID      string
+	service     string
+	endpoint    string
+	rawEndpoint string
+	parent      string
+	status      string
+	attributes  map[string]string
+}
+
+var otlpExportGolden = []wantSpan{
+	{
+		traceID:    "0102030405060708090a0b0c0d0e0f10",
+		spanID:     "1111111111111111",
+		service:    "api-gateway",
+		endpoint:   "/orders",
+		status:     "Unset",
+		attributes: map[string]string{"http.route": "/orders", "order_id": "1234"},
+	},
+	{
+		traceID:     "0102030405060708090a0b0c0d0e0f10",
+		spanID:      "2222222222222222",
+		service:     "order-processing",
+		endpoint:    "/orders/{id}",
+		rawEndpoint: "/orders/1234",
+		parent:      "1111111111111111",
+		status:      "Error",
+		attributes:  map[string]string{"url.path": "/orders/1234", "retries": "2"},
+	},
+	{
+		traceID:  "0102030405060708090a0b0c0d0e0f10",
+		spanID:   "3333333333333333",
+		service:  "order-processing",
+		endpoint: "charge-card",
+		parent:   "2222222222222222",
+		status:   "Unset",
+	},
+	{
+		traceID:    "a0a0a0a0a0a0a0a0a0a0a0a0a0a0a0a0",
+		spanID:     "4444444444444444",
+		service:    "unknown",
+		endpoint:   "Checkout",
+		status:     "Unset",
+		attributes: map[string]string{"rpc.method": "Checkout"},
+	},
+}
+
+func loadExportRequest(t *testing.T, path string) ptraceotlp.ExportRequest {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := ptraceotlp.NewExportRequest()
+	if err := req.UnmarshalJSON(data); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return req
+}
+
+func TestIngestTracesGolden(t *testing.T) {
+	s := NewControlPlaneServer(nil, nil)
+	sub, unsubscribe, err := s.subscribe(defaultSubscriberClass, "", []string{eventTypeSpan})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	s.ingestTraces(loadExportRequest(t, "testdata/otlp_export.json").Traces())
+
+	if got := len(s.traces.traces); got != 2 {
+		t.Fatalf("stored %d traces, want 2", got)
+	}
+	for _, want := range otlpExportGolden {
+		trace, ok := s.traces.traces[want.traceID]
+		if !ok {
+			t.Fatalf("trace %s was not stored", want.traceID)
+		}
+		span, ok := trace.Spans[want.spanID]
+		if !ok {
+			t.Errorf("span %s was not stored", want.spanID)
+			continue
+		}
+		if span.ServiceName != want.service || span.Endpoint != want.endpoint {
+			t.Errorf("span %s stored as %s%s, want %s%s", want.spanID, span.ServiceName, span.Endpoint, want.service, want.endpoint)
+		}
+		if span.Span.GetParentSpanId() != want.parent {
+			t.Errorf("span %s parent = %s, want %s", want.spanID, span.Span.GetParentSpanId(), want.parent)
+		}
+		if span.Span.GetStatusCode() != want.status {
+			t.Errorf("span %s status = %s, want %s", want.spanID, span.Span.GetStatusCode(), want.status)
+		}
+		if !equalAttributes(span.Attributes, want.attributes) {
+			t.Errorf("span %s attributes = %v, want %v", want.spanID, span.Attributes, want.attributes)
+		}
+	}
+
+	// Span events carry the same endpoints, plus the raw one when
+	// normalization changed it.
+	for i, want := range otlpExportGolden {
+		var event *pb.TraceEvent
+		select {
+		case event = <-sub.ch:
+		default:
+			t.Fatalf("got %d span events, want %d", i, len(otlpExportGolden))
+		}
+		if event.GetTraceId() != want.traceID || event.GetEndpoint() != want.endpoint || event.GetRawEndpoint() != want.rawEndpoint {
+			t.Errorf("event %d = %s %s (raw %q), want %s %s (raw %q)", i,
+				event.GetTraceId(), event.GetEndpoint(), event.GetRawEndpoint(),
+				want.traceID, want.endpoint, want.rawEndpoint)
+		}
+	}
+}
+
+func equalAttributes(got, want map[string]string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for k, v := range want {
+		if got[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -19,11 +19,34 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ControlPlane_RegisterBreakpoint_FullMethodName = "/controlplane.ControlPlane/RegisterBreakpoint"
-	ControlPlane_ListBreakpoints_FullMethodName    = "/controlplane.ControlPlane/ListBreakpoints"
-	ControlPlane_DeleteBreakPoint_FullMethodName   = "/controlplane.ControlPlane/DeleteBreakPoint"
-	ControlPlane_GetSnapshot_FullMethodName        = "/controlplane.ControlPlane/GetSnapshot"
-	ControlPlane_StreamTraces_FullMethodName       = "/controlplane.ControlPlane/StreamTraces"
+	ControlPlane_RegisterBreakpoint_FullMethodName     = "/controlplane.ControlPlane/RegisterBreakpoint"
+	ControlPlane_CheckBreakpoint_FullMethodName        = "/controlplane.ControlPlane/CheckBreakpoint"
+	ControlPlane_ListBreakpoints_FullMethodName        = "/controlplane.ControlPlane/ListBreakpoints"
+	ControlPlane_DeleteBreakPoint_FullMethodName       = "/controlplane.ControlPlane/DeleteBreakPoint"
+	ControlPlane_SetBreakpointEnabled_FullMethodName   = "/controlplane.ControlPlane/SetBreakpointEnabled"
+	ControlPlane_BulkUpdateBreakpoints_FullMethodName  = "/controlplane.ControlPlane/BulkUpdateBreakpoints"
+	ControlPlane_GetSnapshot_FullMethodName            = "/controlplane.ControlPlane/GetSnapshot"
+	ControlPlane_RecordSnapshot_FullMethodName         = "/controlplane.ControlPlane/RecordSnapshot"
+	ControlPlane_PurgeSnapshots_FullMethodName         = "/controlplane.ControlPlane/PurgeSnapshots"
+	ControlPlane_DeleteSnapshot_FullMethodName         = "/controlplane.ControlPlane/DeleteSnapshot"
+	ControlPlane_WatchSnapshots_FullMethodName         = "/controlplane.ControlPlane/WatchSnapshots"
+	ControlPlane_DiffSnapshots_FullMethodName          = "/controlplane.ControlPlane/DiffSnapshots"
+	ControlPlane_ListSnapshots_FullMethodName          = "/controlplane.ControlPlane/ListSnapshots"
+	ControlPlane_StreamTraces_FullMethodName           = "/controlplane.ControlPlane/StreamTraces"
+	ControlPlane_StreamTrace_FullMethodName            = "/controlplane.ControlPlane/StreamTrace"
+	ControlPlane_RegisterTraceAlias_FullMethodName     = "/controlplane.ControlPlane/RegisterTraceAlias"
+	ControlPlane_SetSamplingRule_FullMethodName        = "/controlplane.ControlPlane/SetSamplingRule"
+	ControlPlane_GetSamplingPolicy_FullMethodName      = "/controlplane.ControlPlane/GetSamplingPolicy"
+	ControlPlane_DeleteSamplingRule_FullMethodName     = "/controlplane.ControlPlane/DeleteSamplingRule"
+	ControlPlane_GetSupportBundle_FullMethodName       = "/controlplane.ControlPlane/GetSupportBundle"
+	ControlPlane_SetEndpointRewrite_FullMethodName     = "/controlplane.ControlPlane/SetEndpointRewrite"
+	ControlPlane_GetEndpointRewrites_FullMethodName    = "/controlplane.ControlPlane/GetEndpointRewrites"
+	ControlPlane_DeleteEndpointRewrite_FullMethodName  = "/controlplane.ControlPlane/DeleteEndpointRewrite"
+	ControlPlane_SetSubscriberClass_FullMethodName     = "/controlplane.ControlPlane/SetSubscriberClass"
+	ControlPlane_ListSubscriberClasses_FullMethodName  = "/controlplane.ControlPlane/ListSubscriberClasses"
+	ControlPlane_GetBreakpointAnalytics_FullMethodName = "/controlplane.ControlPlane/GetBreakpointAnalytics"
+	ControlPlane_GetTrace_FullMethodName               = "/controlplane.ControlPlane/GetTrace"
+	ControlPlane_GetStorageStats_FullMethodName        = "/controlplane.ControlPlane/GetStorageStats"
 )
 
 // ControlPlaneClient is the client API for ControlPlane service.
@@ -31,10 +54,33 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ControlPlaneClient interface {
 	RegisterBreakpoint(ctx context.Context, in *RegisterBreakPointRequest, opts ...grpc.CallOption) (*RegisterBreakPointResponse, error)
+	CheckBreakpoint(ctx context.Context, in *CheckBreakpointRequest, opts ...grpc.CallOption) (*CheckBreakpointResponse, error)
 	ListBreakpoints(ctx context.Context, in *ListBreakpointsRequest, opts ...grpc.CallOption) (*ListBreakpointsResponse, error)
 	DeleteBreakPoint(ctx context.Context, in *DeleteBreakPointRequest, opts ...grpc.CallOption) (*DeleteBreakPointResponse, error)
+	SetBreakpointEnabled(ctx context.Context, in *SetBreakpointEnabledRequest, opts ...grpc.CallOption) (*SetBreakpointEnabledResponse, error)
+	BulkUpdateBreakpoints(ctx context.Context, in *BulkUpdateBreakpointsRequest, opts ...grpc.CallOption) (*BulkUpdateBreakpointsResponse, error)
 	GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (*GetSnapshotResponse, error)
+	RecordSnapshot(ctx context.Context, in *RecordSnapshotRequest, opts ...grpc.CallOption) (*RecordSnapshotResponse, error)
+	PurgeSnapshots(ctx context.Context, in *PurgeSnapshotsRequest, opts ...grpc.CallOption) (*PurgeSnapshotsResponse, error)
+	DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*DeleteSnapshotResponse, error)
+	WatchSnapshots(ctx context.Context, in *WatchSnapshotsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Snapshot], error)
+	DiffSnapshots(ctx context.Context, in *DiffSnapshotsRequest, opts ...grpc.CallOption) (*DiffSnapshotsResponse, error)
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
 	StreamTraces(ctx context.Context, in *StreamTracesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TraceEvent], error)
+	StreamTrace(ctx context.Context, in *StreamTraceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TraceEvent], error)
+	RegisterTraceAlias(ctx context.Context, in *RegisterTraceAliasRequest, opts ...grpc.CallOption) (*RegisterTraceAliasResponse, error)
+	SetSamplingRule(ctx context.Context, in *SetSamplingRuleRequest, opts ...grpc.CallOption) (*SetSamplingRuleResponse, error)
+	GetSamplingPolicy(ctx context.Context, in *GetSamplingPolicyRequest, opts ...grpc.CallOption) (*GetSamplingPolicyResponse, error)
+	DeleteSamplingRule(ctx context.Context, in *DeleteSamplingRuleRequest, opts ...grpc.CallOption) (*DeleteSamplingRuleResponse, error)
+	GetSupportBundle(ctx context.Context, in *GetSupportBundleRequest, opts ...grpc.CallOption) (*GetSupportBundleResponse, error)
+	SetEndpointRewrite(ctx context.Context, in *SetEndpointRewriteRequest, opts ...grpc.CallOption) (*SetEndpointRewriteResponse, error)
+	GetEndpointRewrites(ctx context.Context, in *GetEndpointRewritesRequest, opts ...grpc.CallOption) (*GetEndpointRewritesResponse, error)
+	DeleteEndpointRewrite(ctx context.Context, in *DeleteEndpointRewriteRequest, opts ...grpc.CallOption) (*DeleteEndpointRewriteResponse, error)
+	SetSubscriberClass(ctx context.Context, in *SetSubscriberClassRequest, opts ...grpc.CallOption) (*SetSubscriberClassResponse, error)
+	ListSubscriberClasses(ctx context.Context, in *ListSubscriberClassesRequest, opts ...grpc.CallOption) (*ListSubscriberClassesResponse, error)
+	GetBreakpointAnalytics(ctx context.Context, in *GetBreakpointAnalyticsRequest, opts ...grpc.CallOption) (*GetBreakpointAnalyticsResponse, error)
+	GetTrace(ctx context.Context, in *GetTraceRequest, opts ...grpc.CallOption) (*GetTraceResponse, error)
+	GetStorageStats(ctx context.Context, in *GetStorageStatsRequest, opts ...grpc.CallOption) (*GetStorageStatsResponse, error)
 }
 
 type controlPlaneClient struct {
@@ -55,6 +101,16 @@ func (c *controlPlaneClient) RegisterBreakpoint(ctx context.Context, in *Registe
 	return out, nil
 }
 
+func (c *controlPlaneClient) CheckBreakpoint(ctx context.Context, in *CheckBreakpointRequest, opts ...grpc.CallOption) (*CheckBreakpointResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckBreakpointResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_CheckBreakpoint_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *controlPlaneClient) ListBreakpoints(ctx context.Context, in *ListBreakpointsRequest, opts ...grpc.CallOption) (*ListBreakpointsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListBreakpointsResponse)
@@ -75,6 +131,26 @@ func (c *controlPlaneClient) DeleteBreakPoint(ctx context.Context, in *DeleteBre
 	return out, nil
 }
 
+func (c *controlPlaneClient) SetBreakpointEnabled(ctx context.Context, in *SetBreakpointEnabledRequest, opts ...grpc.CallOption) (*SetBreakpointEnabledResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetBreakpointEnabledResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_SetBreakpointEnabled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) BulkUpdateBreakpoints(ctx context.Context, in *BulkUpdateBreakpointsRequest, opts ...grpc.CallOption) (*BulkUpdateBreakpointsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BulkUpdateBreakpointsResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_BulkUpdateBreakpoints_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *controlPlaneClient) GetSnapshot(ctx context.Context, in *GetSnapshotRequest, opts ...grpc.CallOption) (*GetSnapshotResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetSnapshotResponse)
@@ -85,9 +161,78 @@ func (c *controlPlaneClient) GetSnapshot(ctx context.Context, in *GetSnapshotReq
 	return out, nil
 }
 
+func (c *controlPlaneClient) RecordSnapshot(ctx context.Context, in *RecordSnapshotRequest, opts ...grpc.CallOption) (*RecordSnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecordSnapshotResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_RecordSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) PurgeSnapshots(ctx context.Context, in *PurgeSnapshotsRequest, opts ...grpc.CallOption) (*PurgeSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeSnapshotsResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_PurgeSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*DeleteSnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSnapshotResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_DeleteSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) WatchSnapshots(ctx context.Context, in *WatchSnapshotsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Snapshot], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ControlPlane_ServiceDesc.Streams[0], ControlPlane_WatchSnapshots_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchSnapshotsRequest, Snapshot]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlPlane_WatchSnapshotsClient = grpc.ServerStreamingClient[Snapshot]
+
+func (c *controlPlaneClient) DiffSnapshots(ctx context.Context, in *DiffSnapshotsRequest, opts ...grpc.CallOption) (*DiffSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffSnapshotsResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_DiffSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_ListSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *controlPlaneClient) StreamTraces(ctx context.Context, in *StreamTracesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TraceEvent], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &ControlPlane_ServiceDesc.Streams[0], ControlPlane_StreamTraces_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &ControlPlane_ServiceDesc.Streams[1], ControlPlane_StreamTraces_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -104,15 +249,187 @@ func (c *controlPlaneClient) StreamTraces(ctx context.Context, in *StreamTracesR
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ControlPlane_StreamTracesClient = grpc.ServerStreamingClient[TraceEvent]
 
+func (c *controlPlaneClient) StreamTrace(ctx context.Context, in *StreamTraceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TraceEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ControlPlane_ServiceDesc.Streams[2], ControlPlane_StreamTrace_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTraceRequest, TraceEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlPlane_StreamTraceClient = grpc.ServerStreamingClient[TraceEvent]
+
+func (c *controlPlaneClient) RegisterTraceAlias(ctx context.Context, in *RegisterTraceAliasRequest, opts ...grpc.CallOption) (*RegisterTraceAliasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterTraceAliasResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_RegisterTraceAlias_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) SetSamplingRule(ctx context.Context, in *SetSamplingRuleRequest, opts ...grpc.CallOption) (*SetSamplingRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetSamplingRuleResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_SetSamplingRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetSamplingPolicy(ctx context.Context, in *GetSamplingPolicyRequest, opts ...grpc.CallOption) (*GetSamplingPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSamplingPolicyResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_GetSamplingPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) DeleteSamplingRule(ctx context.Context, in *DeleteSamplingRuleRequest, opts ...grpc.CallOption) (*DeleteSamplingRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSamplingRuleResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_DeleteSamplingRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetSupportBundle(ctx context.Context, in *GetSupportBundleRequest, opts ...grpc.CallOption) (*GetSupportBundleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSupportBundleResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_GetSupportBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) SetEndpointRewrite(ctx context.Context, in *SetEndpointRewriteRequest, opts ...grpc.CallOption) (*SetEndpointRewriteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetEndpointRewriteResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_SetEndpointRewrite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetEndpointRewrites(ctx context.Context, in *GetEndpointRewritesRequest, opts ...grpc.CallOption) (*GetEndpointRewritesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetEndpointRewritesResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_GetEndpointRewrites_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) DeleteEndpointRewrite(ctx context.Context, in *DeleteEndpointRewriteRequest, opts ...grpc.CallOption) (*DeleteEndpointRewriteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteEndpointRewriteResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_DeleteEndpointRewrite_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) SetSubscriberClass(ctx context.Context, in *SetSubscriberClassRequest, opts ...grpc.CallOption) (*SetSubscriberClassResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetSubscriberClassResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_SetSubscriberClass_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) ListSubscriberClasses(ctx context.Context, in *ListSubscriberClassesRequest, opts ...grpc.CallOption) (*ListSubscriberClassesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSubscriberClassesResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_ListSubscriberClasses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetBreakpointAnalytics(ctx context.Context, in *GetBreakpointAnalyticsRequest, opts ...grpc.CallOption) (*GetBreakpointAnalyticsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBreakpointAnalyticsResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_GetBreakpointAnalytics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetTrace(ctx context.Context, in *GetTraceRequest, opts ...grpc.CallOption) (*GetTraceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTraceResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_GetTrace_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlPlaneClient) GetStorageStats(ctx context.Context, in *GetStorageStatsRequest, opts ...grpc.CallOption) (*GetStorageStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStorageStatsResponse)
+	err := c.cc.Invoke(ctx, ControlPlane_GetStorageStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ControlPlaneServer is the server API for ControlPlane service.
 // All implementations must embed UnimplementedControlPlaneServer
 // for forward compatibility.
 type ControlPlaneServer interface {
 	RegisterBreakpoint(context.Context, *RegisterBreakPointRequest) (*RegisterBreakPointResponse, error)
+	CheckBreakpoint(context.Context, *CheckBreakpointRequest) (*CheckBreakpointResponse, error)
 	ListBreakpoints(context.Context, *ListBreakpointsRequest) (*ListBreakpointsResponse, error)
 	DeleteBreakPoint(context.Context, *DeleteBreakPointRequest) (*DeleteBreakPointResponse, error)
+	SetBreakpointEnabled(context.Context, *SetBreakpointEnabledRequest) (*SetBreakpointEnabledResponse, error)
+	BulkUpdateBreakpoints(context.Context, *BulkUpdateBreakpointsRequest) (*BulkUpdateBreakpointsResponse, error)
 	GetSnapshot(context.Context, *GetSnapshotRequest) (*GetSnapshotResponse, error)
+	RecordSnapshot(context.Context, *RecordSnapshotRequest) (*RecordSnapshotResponse, error)
+	PurgeSnapshots(context.Context, *PurgeSnapshotsRequest) (*PurgeSnapshotsResponse, error)
+	DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*DeleteSnapshotResponse, error)
+	WatchSnapshots(*WatchSnapshotsRequest, grpc.ServerStreamingServer[Snapshot]) error
+	DiffSnapshots(context.Context, *DiffSnapshotsRequest) (*DiffSnapshotsResponse, error)
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
 	StreamTraces(*StreamTracesRequest, grpc.ServerStreamingServer[TraceEvent]) error
+	StreamTrace(*StreamTraceRequest, grpc.ServerStreamingServer[TraceEvent]) error
+	RegisterTraceAlias(context.Context, *RegisterTraceAliasRequest) (*RegisterTraceAliasResponse, error)
+	SetSamplingRule(context.Context, *SetSamplingRuleRequest) (*SetSamplingRuleResponse, error)
+	GetSamplingPolicy(context.Context, *GetSamplingPolicyRequest) (*GetSamplingPolicyResponse, error)
+	DeleteSamplingRule(context.Context, *DeleteSamplingRuleRequest) (*DeleteSamplingRuleResponse, error)
+	GetSupportBundle(context.Context, *GetSupportBundleRequest) (*GetSupportBundleResponse, error)
+	SetEndpointRewrite(context.Context, *SetEndpointRewriteRequest) (*SetEndpointRewriteResponse, error)
+	GetEndpointRewrites(context.Context, *GetEndpointRewritesRequest) (*GetEndpointRewritesResponse, error)
+	DeleteEndpointRewrite(context.Context, *DeleteEndpointRewriteRequest) (*DeleteEndpointRewriteResponse, error)
+	SetSubscriberClass(context.Context, *SetSubscriberClassRequest) (*SetSubscriberClassResponse, error)
+	ListSubscriberClasses(context.Context, *ListSubscriberClassesRequest) (*ListSubscriberClassesResponse, error)
+	GetBreakpointAnalytics(context.Context, *GetBreakpointAnalyticsRequest) (*GetBreakpointAnalyticsResponse, error)
+	GetTrace(context.Context, *GetTraceRequest) (*GetTraceResponse, error)
+	GetStorageStats(context.Context, *GetStorageStatsRequest) (*GetStorageStatsResponse, error)
 	mustEmbedUnimplementedControlPlaneServer()
 }
 
@@ -126,18 +443,87 @@ type UnimplementedControlPlaneServer struct{}
 func (UnimplementedControlPlaneServer) RegisterBreakpoint(context.Context, *RegisterBreakPointRequest) (*RegisterBreakPointResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RegisterBreakpoint not implemented")
 }
+func (UnimplementedControlPlaneServer) CheckBreakpoint(context.Context, *CheckBreakpointRequest) (*CheckBreakpointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckBreakpoint not implemented")
+}
 func (UnimplementedControlPlaneServer) ListBreakpoints(context.Context, *ListBreakpointsRequest) (*ListBreakpointsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListBreakpoints not implemented")
 }
 func (UnimplementedControlPlaneServer) DeleteBreakPoint(context.Context, *DeleteBreakPointRequest) (*DeleteBreakPointResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteBreakPoint not implemented")
 }
+func (UnimplementedControlPlaneServer) SetBreakpointEnabled(context.Context, *SetBreakpointEnabledRequest) (*SetBreakpointEnabledResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBreakpointEnabled not implemented")
+}
+func (UnimplementedControlPlaneServer) BulkUpdateBreakpoints(context.Context, *BulkUpdateBreakpointsRequest) (*BulkUpdateBreakpointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkUpdateBreakpoints not implemented")
+}
 func (UnimplementedControlPlaneServer) GetSnapshot(context.Context, *GetSnapshotRequest) (*GetSnapshotResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
 }
+func (UnimplementedControlPlaneServer) RecordSnapshot(context.Context, *RecordSnapshotRequest) (*RecordSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordSnapshot not implemented")
+}
+func (UnimplementedControlPlaneServer) PurgeSnapshots(context.Context, *PurgeSnapshotsRequest) (*PurgeSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeSnapshots not implemented")
+}
+func (UnimplementedControlPlaneServer) DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*DeleteSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSnapshot not implemented")
+}
+func (UnimplementedControlPlaneServer) WatchSnapshots(*WatchSnapshotsRequest, grpc.ServerStreamingServer[Snapshot]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSnapshots not implemented")
+}
+func (UnimplementedControlPlaneServer) DiffSnapshots(context.Context, *DiffSnapshotsRequest) (*DiffSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffSnapshots not implemented")
+}
+func (UnimplementedControlPlaneServer) ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
 func (UnimplementedControlPlaneServer) StreamTraces(*StreamTracesRequest, grpc.ServerStreamingServer[TraceEvent]) error {
 	return status.Errorf(codes.Unimplemented, "method StreamTraces not implemented")
 }
+func (UnimplementedControlPlaneServer) StreamTrace(*StreamTraceRequest, grpc.ServerStreamingServer[TraceEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTrace not implemented")
+}
+func (UnimplementedControlPlaneServer) RegisterTraceAlias(context.Context, *RegisterTraceAliasRequest) (*RegisterTraceAliasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterTraceAlias not implemented")
+}
+func (UnimplementedControlPlaneServer) SetSamplingRule(context.Context, *SetSamplingRuleRequest) (*SetSamplingRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSamplingRule not implemented")
+}
+func (UnimplementedControlPlaneServer) GetSamplingPolicy(context.Context, *GetSamplingPolicyRequest) (*GetSamplingPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSamplingPolicy not implemented")
+}
+func (UnimplementedControlPlaneServer) DeleteSamplingRule(context.Context, *DeleteSamplingRuleRequest) (*DeleteSamplingRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSamplingRule not implemented")
+}
+func (UnimplementedControlPlaneServer) GetSupportBundle(context.Context, *GetSupportBundleRequest) (*GetSupportBundleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSupportBundle not implemented")
+}
+func (UnimplementedControlPlaneServer) SetEndpointRewrite(context.Context, *SetEndpointRewriteRequest) (*SetEndpointRewriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetEndpointRewrite not implemented")
+}
+func (UnimplementedControlPlaneServer) GetEndpointRewrites(context.Context, *GetEndpointRewritesRequest) (*GetEndpointRewritesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEndpointRewrites not implemented")
+}
+func (UnimplementedControlPlaneServer) DeleteEndpointRewrite(context.Context, *DeleteEndpointRewriteRequest) (*DeleteEndpointRewriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteEndpointRewrite not implemented")
+}
+func (UnimplementedControlPlaneServer) SetSubscriberClass(context.Context, *SetSubscriberClassRequest) (*SetSubscriberClassResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSubscriberClass not implemented")
+}
+func (UnimplementedControlPlaneServer) ListSubscriberClasses(context.Context, *ListSubscriberClassesRequest) (*ListSubscriberClassesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscriberClasses not implemented")
+}
+func (UnimplementedControlPlaneServer) GetBreakpointAnalytics(context.Context, *GetBreakpointAnalyticsRequest) (*GetBreakpointAnalyticsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBreakpointAnalytics not implemented")
+}
+func (UnimplementedControlPlaneServer) GetTrace(context.Context, *GetTraceRequest) (*GetTraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrace not implemented")
+}
+func (UnimplementedControlPlaneServer) GetStorageStats(context.Context, *GetStorageStatsRequest) (*GetStorageStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStorageStats not implemented")
+}
 func (UnimplementedControlPlaneServer) mustEmbedUnimplementedControlPlaneServer() {}
 func (UnimplementedControlPlaneServer) testEmbeddedByValue()                      {}
 
@@ -177,6 +563,24 @@ func _ControlPlane_RegisterBreakpoint_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ControlPlane_CheckBreakpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckBreakpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).CheckBreakpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_CheckBreakpoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).CheckBreakpoint(ctx, req.(*CheckBreakpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ControlPlane_ListBreakpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListBreakpointsRequest)
 	if err := dec(in); err != nil {
@@ -213,6 +617,42 @@ func _ControlPlane_DeleteBreakPoint_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ControlPlane_SetBreakpointEnabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBreakpointEnabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).SetBreakpointEnabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_SetBreakpointEnabled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).SetBreakpointEnabled(ctx, req.(*SetBreakpointEnabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_BulkUpdateBreakpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkUpdateBreakpointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).BulkUpdateBreakpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_BulkUpdateBreakpoints_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).BulkUpdateBreakpoints(ctx, req.(*BulkUpdateBreakpointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ControlPlane_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetSnapshotRequest)
 	if err := dec(in); err != nil {
@@ -231,16 +671,362 @@ func _ControlPlane_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ControlPlane_StreamTraces_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(StreamTracesRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func _ControlPlane_RecordSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return srv.(ControlPlaneServer).StreamTraces(m, &grpc.GenericServerStream[StreamTracesRequest, TraceEvent]{ServerStream: stream})
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).RecordSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_RecordSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).RecordSnapshot(ctx, req.(*RecordSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
-type ControlPlane_StreamTracesServer = grpc.ServerStreamingServer[TraceEvent]
+func _ControlPlane_PurgeSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).PurgeSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_PurgeSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).PurgeSnapshots(ctx, req.(*PurgeSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_DeleteSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).DeleteSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_DeleteSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).DeleteSnapshot(ctx, req.(*DeleteSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_WatchSnapshots_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSnapshotsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControlPlaneServer).WatchSnapshots(m, &grpc.GenericServerStream[WatchSnapshotsRequest, Snapshot]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlPlane_WatchSnapshotsServer = grpc.ServerStreamingServer[Snapshot]
+
+func _ControlPlane_DiffSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).DiffSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_DiffSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).DiffSnapshots(ctx, req.(*DiffSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_StreamTraces_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTracesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControlPlaneServer).StreamTraces(m, &grpc.GenericServerStream[StreamTracesRequest, TraceEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlPlane_StreamTracesServer = grpc.ServerStreamingServer[TraceEvent]
+
+func _ControlPlane_StreamTrace_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTraceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControlPlaneServer).StreamTrace(m, &grpc.GenericServerStream[StreamTraceRequest, TraceEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ControlPlane_StreamTraceServer = grpc.ServerStreamingServer[TraceEvent]
+
+func _ControlPlane_RegisterTraceAlias_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterTraceAliasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).RegisterTraceAlias(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_RegisterTraceAlias_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).RegisterTraceAlias(ctx, req.(*RegisterTraceAliasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_SetSamplingRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSamplingRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).SetSamplingRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_SetSamplingRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).SetSamplingRule(ctx, req.(*SetSamplingRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetSamplingPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSamplingPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetSamplingPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_GetSamplingPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetSamplingPolicy(ctx, req.(*GetSamplingPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_DeleteSamplingRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSamplingRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).DeleteSamplingRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_DeleteSamplingRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).DeleteSamplingRule(ctx, req.(*DeleteSamplingRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetSupportBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSupportBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetSupportBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_GetSupportBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetSupportBundle(ctx, req.(*GetSupportBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_SetEndpointRewrite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetEndpointRewriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).SetEndpointRewrite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_SetEndpointRewrite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).SetEndpointRewrite(ctx, req.(*SetEndpointRewriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetEndpointRewrites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEndpointRewritesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetEndpointRewrites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_GetEndpointRewrites_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetEndpointRewrites(ctx, req.(*GetEndpointRewritesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_DeleteEndpointRewrite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteEndpointRewriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).DeleteEndpointRewrite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_DeleteEndpointRewrite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).DeleteEndpointRewrite(ctx, req.(*DeleteEndpointRewriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_SetSubscriberClass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSubscriberClassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).SetSubscriberClass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_SetSubscriberClass_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).SetSubscriberClass(ctx, req.(*SetSubscriberClassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_ListSubscriberClasses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscriberClassesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).ListSubscriberClasses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_ListSubscriberClasses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).ListSubscriberClasses(ctx, req.(*ListSubscriberClassesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetBreakpointAnalytics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBreakpointAnalyticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetBreakpointAnalytics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_GetBreakpointAnalytics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetBreakpointAnalytics(ctx, req.(*GetBreakpointAnalyticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetTrace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTraceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetTrace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_GetTrace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetTrace(ctx, req.(*GetTraceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ControlPlane_GetStorageStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStorageStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlPlaneServer).GetStorageStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ControlPlane_GetStorageStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlPlaneServer).GetStorageStats(ctx, req.(*GetStorageStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
 // ControlPlane_ServiceDesc is the grpc.ServiceDesc for ControlPlane service.
 // It's only intended for direct use with grpc.RegisterService,
@@ -253,6 +1039,10 @@ var ControlPlane_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RegisterBreakpoint",
 			Handler:    _ControlPlane_RegisterBreakpoint_Handler,
 		},
+		{
+			MethodName: "CheckBreakpoint",
+			Handler:    _ControlPlane_CheckBreakpoint_Handler,
+		},
 		{
 			MethodName: "ListBreakpoints",
 			Handler:    _ControlPlane_ListBreakpoints_Handler,
@@ -261,17 +1051,257 @@ var ControlPlane_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteBreakPoint",
 			Handler:    _ControlPlane_DeleteBreakPoint_Handler,
 		},
+		{
+			MethodName: "SetBreakpointEnabled",
+			Handler:    _ControlPlane_SetBreakpointEnabled_Handler,
+		},
+		{
+			MethodName: "BulkUpdateBreakpoints",
+			Handler:    _ControlPlane_BulkUpdateBreakpoints_Handler,
+		},
 		{
 			MethodName: "GetSnapshot",
 			Handler:    _ControlPlane_GetSnapshot_Handler,
 		},
+		{
+			MethodName: "RecordSnapshot",
+			Handler:    _ControlPlane_RecordSnapshot_Handler,
+		},
+		{
+			MethodName: "PurgeSnapshots",
+			Handler:    _ControlPlane_PurgeSnapshots_Handler,
+		},
+		{
+			MethodName: "DeleteSnapshot",
+			Handler:    _ControlPlane_DeleteSnapshot_Handler,
+		},
+		{
+			MethodName: "DiffSnapshots",
+			Handler:    _ControlPlane_DiffSnapshots_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _ControlPlane_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "RegisterTraceAlias",
+			Handler:    _ControlPlane_RegisterTraceAlias_Handler,
+		},
+		{
+			MethodName: "SetSamplingRule",
+			Handler:    _ControlPlane_SetSamplingRule_Handler,
+		},
+		{
+			MethodName: "GetSamplingPolicy",
+			Handler:    _ControlPlane_GetSamplingPolicy_Handler,
+		},
+		{
+			MethodName: "DeleteSamplingRule",
+			Handler:    _ControlPlane_DeleteSamplingRule_Handler,
+		},
+		{
+			MethodName: "GetSupportBundle",
+			Handler:    _ControlPlane_GetSupportBundle_Handler,
+		},
+		{
+			MethodName: "SetEndpointRewrite",
+			Handler:    _ControlPlane_SetEndpointRewrite_Handler,
+		},
+		{
+			MethodName: "GetEndpointRewrites",
+			Handler:    _ControlPlane_GetEndpointRewrites_Handler,
+		},
+		{
+			MethodName: "DeleteEndpointRewrite",
+			Handler:    _ControlPlane_DeleteEndpointRewrite_Handler,
+		},
+		{
+			MethodName: "SetSubscriberClass",
+			Handler:    _ControlPlane_SetSubscriberClass_Handler,
+		},
+		{
+			MethodName: "ListSubscriberClasses",
+			Handler:    _ControlPlane_ListSubscriberClasses_Handler,
+		},
+		{
+			MethodName: "GetBreakpointAnalytics",
+			Handler:    _ControlPlane_GetBreakpointAnalytics_Handler,
+		},
+		{
+			MethodName: "GetTrace",
+			Handler:    _ControlPlane_GetTrace_Handler,
+		},
+		{
+			MethodName: "GetStorageStats",
+			Handler:    _ControlPlane_GetStorageStats_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSnapshots",
+			Handler:       _ControlPlane_WatchSnapshots_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "StreamTraces",
 			Handler:       _ControlPlane_StreamTraces_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamTrace",
+			Handler:       _ControlPlane_StreamTrace_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "controlplane.proto",
+}
+
+const (
+	Observer_StreamTraces_FullMethodName    = "/controlplane.Observer/StreamTraces"
+	Observer_ListBreakpoints_FullMethodName = "/controlplane.Observer/ListBreakpoints"
+)
+
+// ObserverClient is the client API for Observer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Read-only view for dashboards. Served on its own port; attribute and
+// condition values are always redacted.
+type ObserverClient interface {
+	StreamTraces(ctx context.Context, in *StreamTracesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TraceEvent], error)
+	ListBreakpoints(ctx context.Context, in *ListBreakpointsRequest, opts ...grpc.CallOption) (*ListBreakpointsResponse, error)
+}
+
+type observerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewObserverClient(cc grpc.ClientConnInterface) ObserverClient {
+	return &observerClient{cc}
+}
+
+func (c *observerClient) StreamTraces(ctx context.Context, in *StreamTracesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TraceEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Observer_ServiceDesc.Streams[0], Observer_StreamTraces_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTracesRequest, TraceEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Observer_StreamTracesClient = grpc.ServerStreamingClient[TraceEvent]
+
+func (c *observerClient) ListBreakpoints(ctx context.Context, in *ListBreakpointsRequest, opts ...grpc.CallOption) (*ListBreakpointsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBreakpointsResponse)
+	err := c.cc.Invoke(ctx, Observer_ListBreakpoints_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ObserverServer is the server API for Observer service.
+// All implementations must embed UnimplementedObserverServer
+// for forward compatibility.
+//
+// Read-only view for dashboards. Served on its own port; attribute and
+// condition values are always redacted.
+type ObserverServer interface {
+	StreamTraces(*StreamTracesRequest, grpc.ServerStreamingServer[TraceEvent]) error
+	ListBreakpoints(context.Context, *ListBreakpointsRequest) (*ListBreakpointsResponse, error)
+	mustEmbedUnimplementedObserverServer()
+}
+
+// UnimplementedObserverServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedObserverServer struct{}
+
+func (UnimplementedObserverServer) StreamTraces(*StreamTracesRequest, grpc.ServerStreamingServer[TraceEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTraces not implemented")
+}
+func (UnimplementedObserverServer) ListBreakpoints(context.Context, *ListBreakpointsRequest) (*ListBreakpointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBreakpoints not implemented")
+}
+func (UnimplementedObserverServer) mustEmbedUnimplementedObserverServer() {}
+func (UnimplementedObserverServer) testEmbeddedByValue()                  {}
+
+// UnsafeObserverServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ObserverServer will
+// result in compilation errors.
+type UnsafeObserverServer interface {
+	mustEmbedUnimplementedObserverServer()
+}
+
+func RegisterObserverServer(s grpc.ServiceRegistrar, srv ObserverServer) {
+	// If the following call pancis, it indicates UnimplementedObserverServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Observer_ServiceDesc, srv)
+}
+
+func _Observer_StreamTraces_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTracesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ObserverServer).StreamTraces(m, &grpc.GenericServerStream[StreamTracesRequest, TraceEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Observer_StreamTracesServer = grpc.ServerStreamingServer[TraceEvent]
+
+func _Observer_ListBreakpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBreakpointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ObserverServer).ListBreakpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Observer_ListBreakpoints_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ObserverServer).ListBreakpoints(ctx, req.(*ListBreakpointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Observer_ServiceDesc is the grpc.ServiceDesc for Observer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Observer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "controlplane.Observer",
+	HandlerType: (*ObserverServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListBreakpoints",
+			Handler:    _Observer_ListBreakpoints_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTraces",
+			Handler:       _Observer_StreamTraces_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "controlplane.proto",
 }
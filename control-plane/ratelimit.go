@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// methodLimit is a token bucket budget for one RPC, per client.
+type methodLimit struct {
+	Rate  float64 // tokens per second
+	Burst float64
+}
+
+// defaultUnaryLimit applies to every unary RPC without its own entry. It is
+// generous on purpose: the point is to stop runaway scripts, not operators.
+var defaultUnaryLimit = methodLimit{Rate: 20, Burst: 40}
+
+var methodLimits = map[string]methodLimit{
+	pb.ControlPlane_RegisterBreakpoint_FullMethodName: {Rate: 5, Burst: 10},
+	pb.ControlPlane_GetSnapshot_FullMethodName:        {Rate: 5, Burst: 10},
+	// SDKs post a snapshot on every hit, so this is sized for service
+	// traffic rather than operators.
+	pb.ControlPlane_RecordSnapshot_FullMethodName: {Rate: 100, Burst: 200},
+}
+
+// unlimitedMethods are never rate limited. SDKs call CheckBreakpoint on
+// every request, and every pod behind one node shares a peer IP, so any
+// per-client limit would silently turn breakpoint hits into misses.
+var unlimitedMethods = map[string]bool{
+	pb.ControlPlane_CheckBreakpoint_FullMethodName: true,
+}
+
+const (
+	maxStreamsPerClient = 5
+	maxStreamsTotal     = 100
+	bucketIdleTimeout   = 10 * time.Minute
+)
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter enforces per-client token buckets on unary RPCs and caps the
+// number of concurrent server streams. Clients are identified by peer IP
+// since the API has no caller identity yet.
+type RateLimiter struct {
+	mu           sync.Mutex
+	buckets      map[string]*tokenBucket
+	streams      map[string]int
+	totalStreams int
+	lastSweep    time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets:   make(map[string]*tokenBucket),
+		streams:   make(map[string]int),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *RateLimiter) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if unlimitedMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	limit, ok := methodLimits[info.FullMethod]
+	if !ok {
+		limit = defaultUnaryLimit
+	}
+
+	if retryAfter, ok := l.allow(clientKey(ctx)+info.FullMethod, limit); !ok {
+		return nil, resourceExhausted(fmt.Sprintf("rate limit exceeded for %s", info.FullMethod), retryAfter)
+	}
+	return handler(ctx, req)
+}
+
+func (l *RateLimiter) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	client := clientKey(ss.Context())
+
+	l.mu.Lock()
+	if l.totalStreams >= maxStreamsTotal {
+		l.mu.Unlock()
+		return resourceExhausted("control plane is at its stream limit", 30*time.Second)
+	}
+	if l.streams[client] >= maxStreamsPerClient {
+		l.mu.Unlock()
+		return resourceExhausted(fmt.Sprintf("at most %d concurrent streams per client", maxStreamsPerClient), 30*time.Second)
+	}
+	l.streams[client]++
+	l.totalStreams++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.streams[client]--
+		if l.streams[client] == 0 {
+			delete(l.streams, client)
+		}
+		l.totalStreams--
+		l.mu.Unlock()
+	}()
+
+	return handler(srv, ss)
+}
+
+// allow takes a token from the bucket for key, returning how long to wait
+// before retrying when the bucket is empty.
+func (l *RateLimiter) allow(key string, limit methodLimit) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > bucketIdleTimeout {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > bucketIdleTimeout {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: limit.Burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * limit.Rate
+	if b.tokens > limit.Burst {
+		b.tokens = limit.Burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+func clientKey(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func resourceExhausted(msg string, retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, msg)
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
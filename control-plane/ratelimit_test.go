@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckBreakpointIsNotRateLimited(t *testing.T) {
+	l := NewRateLimiter()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	check := &grpc.UnaryServerInfo{FullMethod: pb.ControlPlane_CheckBreakpoint_FullMethodName}
+	for i := 0; i < 10000; i++ {
+		if _, err := l.UnaryInterceptor(context.Background(), nil, check, handler); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+
+	register := &grpc.UnaryServerInfo{FullMethod: pb.ControlPlane_RegisterBreakpoint_FullMethodName}
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		_, err = l.UnaryInterceptor(context.Background(), nil, register, handler)
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("RegisterBreakpoint was never limited: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// requestIDHeader carries a request's correlation ID. A caller may set
+	// it to tie control-plane logs to its own; otherwise one is assigned.
+	// It is echoed back in the response headers either way.
+	requestIDHeader = "x-request-id"
+
+	// userHeader names the person behind a call, for CLIs and dashboards
+	// that know it. It is informational, not authentication.
+	userHeader = "x-tracery-user"
+)
+
+// quietMethods run on every request of an instrumented service, so they are
+// only logged when they fail. They are still counted.
+var quietMethods = map[string]bool{
+	"/controlplane.ControlPlane/CheckBreakpoint": true,
+}
+
+type requestInfoKey struct{}
+
+// requestInfo identifies one RPC to handlers and the interceptors after
+// RequestLogger.
+type requestInfo struct {
+	ID     string
+	Caller string // client address
+	User   string // from userHeader, if sent
+}
+
+// callerInfo returns the request info RequestLogger attached to ctx, or a
+// best-effort one for contexts that did not come through it.
+func callerInfo(ctx context.Context) requestInfo {
+	if info, ok := ctx.Value(requestInfoKey{}).(requestInfo); ok {
+		return info
+	}
+	return requestInfo{Caller: clientKey(ctx)}
+}
+
+// String renders the caller for log lines, e.g. "alice@10.0.0.7".
+func (info requestInfo) String() string {
+	if info.User != "" {
+		return info.User + "@" + info.Caller
+	}
+	return info.Caller
+}
+
+type methodStats struct {
+	Calls      int64            `json:"calls"`
+	Errors     int64            `json:"errors"`
+	Codes      map[string]int64 `json:"codes"`
+	TotalNanos int64            `json:"total_ns"`
+	MaxNanos   int64            `json:"max_ns"`
+}
+
+// RequestLogger assigns every RPC a request ID, puts the caller's identity
+// in its context, logs one line per call with its outcome and latency, and
+// keeps per-method counts for support bundles.
+type RequestLogger struct {
+	mu    sync.Mutex
+	stats map[string]*methodStats
+}
+
+func NewRequestLogger() *RequestLogger {
+	return &RequestLogger{stats: make(map[string]*methodStats)}
+}
+
+func (l *RequestLogger) begin(ctx context.Context) (context.Context, requestInfo) {
+	info := requestInfo{Caller: clientKey(ctx)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(requestIDHeader); len(v) > 0 && v[0] != "" && len(v[0]) <= 128 {
+			info.ID = v[0]
+		}
+		if v := md.Get(userHeader); len(v) > 0 {
+			info.User = v[0]
+		}
+	}
+	if info.ID == "" {
+		info.ID = uuid.New().String()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, info.ID))
+	return context.WithValue(ctx, requestInfoKey{}, info), info
+}
+
+func (l *RequestLogger) finish(method string, info requestInfo, start time.Time, err error) {
+	elapsed := time.Since(start)
+	code := status.Code(err)
+
+	l.mu.Lock()
+	stats, ok := l.stats[method]
+	if !ok {
+		stats = &methodStats{Codes: make(map[string]int64)}
+		l.stats[method] = stats
+	}
+	stats.Calls++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.Codes[code.String()]++
+	stats.TotalNanos += elapsed.Nanoseconds()
+	if elapsed.Nanoseconds() > stats.MaxNanos {
+		stats.MaxNanos = elapsed.Nanoseconds()
+	}
+	l.mu.Unlock()
+
+	if err == nil && quietMethods[method] {
+		return
+	}
+	log.Printf("[ControlPlane] rpc method=%s request_id=%s caller=%s code=%s duration=%s",
+		method, info.ID, info, code, elapsed.Round(time.Microsecond))
+}
+
+// snapshot returns the per-method counts, sorted by method for stable
+// support bundles.
+func (l *RequestLogger) snapshot() map[string]methodStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	methods := make([]string, 0, len(l.stats))
+	for m := range l.stats {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+
+	out := make(map[string]methodStats, len(methods))
+	for _, m := range methods {
+		stats := *l.stats[m]
+		stats.Codes = make(map[string]int64, len(l.stats[m].Codes))
+		for code, n := range l.stats[m].Codes {
+			stats.Codes[code] = n
+		}
+		out[m] = stats
+	}
+	return out
+}
+
+func (l *RequestLogger) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, reqInfo := l.begin(ctx)
+	resp, err := handler(ctx, req)
+	l.finish(info.FullMethod, reqInfo, start, err)
+	return resp, err
+}
+
+func (l *RequestLogger) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, reqInfo := l.begin(ss.Context())
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	l.finish(info.FullMethod, reqInfo, start, err)
+	return err
+}
+
+// contextStream swaps a server stream's context for one carrying the
+// request info.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"github.com/google/uuid"
+)
+
+// defaultSamplingRate applies to services without a rule. The demo services
+// sample everything, so the control plane does too unless told otherwise.
+const defaultSamplingRate = 1.0
+
+type SamplingRule struct {
+	ID              string
+	ServiceName     string
+	EndPoint        string
+	Rate            float64
+	ForceConditions map[string]string
+	CreatedAt       time.Time
+}
+
+// SetSamplingRule creates or replaces the rule for a service/endpoint pair.
+// Samplers pick it up on their next GetSamplingPolicy poll.
+func (s *ControlPlaneServer) SetSamplingRule(ctx context.Context, req *pb.SetSamplingRuleRequest) (*pb.SetSamplingRuleResponse, error) {
+	if req.GetServiceName() == "" {
+		return &pb.SetSamplingRuleResponse{
+			Success:     false,
+			RespMessage: "service_name is required",
+		}, nil
+	}
+	if req.GetRate() < 0 || req.GetRate() > 1 {
+		return &pb.SetSamplingRuleResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("rate must be between 0 and 1, got %v", req.GetRate()),
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ruleID := uuid.New().String()
+	for id, rule := range s.samplingRules {
+		if rule.ServiceName == req.GetServiceName() && rule.EndPoint == req.GetEndpoint() {
+			ruleID = id
+			break
+		}
+	}
+
+	s.samplingRules[ruleID] = &SamplingRule{
+		ID:              ruleID,
+		ServiceName:     req.GetServiceName(),
+		EndPoint:        req.GetEndpoint(),
+		Rate:            req.GetRate(),
+		ForceConditions: req.GetForceConditions(),
+		CreatedAt:       time.Now(),
+	}
+	s.samplingVersion++
+
+	log.Printf("[ControlPlane] Sampling rule %s: %s%s at %.2f (force: %v)", ruleID, req.GetServiceName(), req.GetEndpoint(), req.GetRate(), req.GetForceConditions())
+
+	return &pb.SetSamplingRuleResponse{
+		RuleId:      ruleID,
+		Success:     true,
+		RespMessage: fmt.Sprintf("Sampling %s%s at %.0f%%", req.GetServiceName(), req.GetEndpoint(), req.GetRate()*100),
+	}, nil
+}
+
+func (s *ControlPlaneServer) GetSamplingPolicy(ctx context.Context, req *pb.GetSamplingPolicyRequest) (*pb.GetSamplingPolicyResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules := make([]*pb.SamplingRule, 0, len(s.samplingRules))
+	for _, rule := range s.samplingRules {
+		if req.GetServiceName() != "" && rule.ServiceName != req.GetServiceName() {
+			continue
+		}
+		rules = append(rules, &pb.SamplingRule{
+			Id:              rule.ID,
+			ServiceName:     rule.ServiceName,
+			Endpoint:        rule.EndPoint,
+			Rate:            rule.Rate,
+			ForceConditions: rule.ForceConditions,
+			CreatedAt:       rule.CreatedAt.Unix(),
+		})
+	}
+
+	return &pb.GetSamplingPolicyResponse{
+		Rules:       rules,
+		DefaultRate: defaultSamplingRate,
+		Version:     s.samplingVersion,
+	}, nil
+}
+
+func (s *ControlPlaneServer) DeleteSamplingRule(ctx context.Context, req *pb.DeleteSamplingRuleRequest) (*pb.DeleteSamplingRuleResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.samplingRules[req.GetRuleId()]; !exists {
+		return &pb.DeleteSamplingRuleResponse{
+			Success:     false,
+			RespMessage: "Sampling rule not found",
+		}, nil
+	}
+
+	delete(s.samplingRules, req.GetRuleId())
+	s.samplingVersion++
+
+	return &pb.DeleteSamplingRuleResponse{
+		Success:     true,
+		RespMessage: "Sampling rule deleted",
+	}, nil
+}
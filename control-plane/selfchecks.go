@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+)
+
+const (
+	eventTypeSelfCheckFailed    = "self_check_failed"
+	eventTypeSelfCheckRecovered = "self_check_recovered"
+
+	selfCheckInterval = 30 * time.Second
+)
+
+// selfCheck is one condition that means the control plane is degraded.
+// Check returns a description of the problem, or "" while healthy.
+// Callers must hold s.mu.
+type selfCheck struct {
+	Name  string
+	Check func(s *ControlPlaneServer, now time.Time) string
+}
+
+// defaultSelfChecks declares the checks run by runSelfChecks. Thresholds
+// come from the environment so operators can tune them per deployment; a
+// threshold of zero turns its check off.
+func defaultSelfChecks() []selfCheck {
+	otlpSilence := envDuration("TRACERY_SELFCHECK_OTLP_SILENCE", 5*time.Minute)
+	rpcErrors := envInt("TRACERY_SELFCHECK_RPC_ERRORS_PER_MINUTE", 20)
+
+	var checks []selfCheck
+	if otlpSilence > 0 {
+		checks = append(checks, selfCheck{
+			Name: "otlp_silent",
+			Check: func(s *ControlPlaneServer, now time.Time) string {
+				last := s.lastSpanAt
+				if last.IsZero() {
+					last = s.startedAt
+				}
+				if now.Sub(last) < otlpSilence {
+					return ""
+				}
+				return fmt.Sprintf("no spans received over OTLP for %s", now.Sub(last).Round(time.Second))
+			},
+		})
+	}
+	if rpcErrors > 0 {
+		checks = append(checks, selfCheck{
+			Name: "rpc_errors",
+			Check: func(s *ControlPlaneServer, now time.Time) string {
+				n := s.errors.countSince(now.Add(-time.Minute))
+				if n < rpcErrors {
+					return ""
+				}
+				return fmt.Sprintf("%d RPC errors in the last minute", n)
+			},
+		})
+	}
+	checks = append(checks,
+		selfCheck{
+			Name: "events_dropped",
+			Check: func(s *ControlPlaneServer, now time.Time) string {
+				var dropped int64
+				for _, class := range s.subscriberClasses {
+					dropped += class.Dropped.Load()
+				}
+				since := dropped - s.selfCheckDropped
+				s.selfCheckDropped = dropped
+				if since == 0 {
+					return ""
+				}
+				return fmt.Sprintf("%d event(s) dropped for slow subscribers since the last check", since)
+			},
+		},
+		selfCheck{
+			Name: "breakpoint_store",
+			Check: func(s *ControlPlaneServer, now time.Time) string {
+				if s.breakpointSyncErr == nil {
+					return ""
+				}
+				return fmt.Sprintf("reloading breakpoints failed: %v", s.breakpointSyncErr)
+			},
+		},
+	)
+	return checks
+}
+
+// runSelfChecks evaluates the self-checks every interval. A check that
+// starts or stops failing publishes an event and, when
+// TRACERY_SELFCHECK_WEBHOOK is set, posts a notification there, so
+// operators hear the debugger is degraded before they need it.
+func (s *ControlPlaneServer) runSelfChecks(checks []selfCheck, interval time.Duration) {
+	webhook := os.Getenv("TRACERY_SELFCHECK_WEBHOOK")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		for _, n := range s.evaluateSelfChecks(checks, now) {
+			if webhook != "" {
+				notifySelfCheck(webhook, n)
+			}
+		}
+	}
+}
+
+// selfCheckNotification is the body posted to the self-check webhook.
+type selfCheckNotification struct {
+	Check   string    `json:"check"`
+	Status  string    `json:"status"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// evaluateSelfChecks runs every check once and returns the ones whose state
+// changed.
+func (s *ControlPlaneServer) evaluateSelfChecks(checks []selfCheck, now time.Time) []selfCheckNotification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed []selfCheckNotification
+	for _, check := range checks {
+		problem := check.Check(s, now)
+		_, wasFailing := s.failingChecks[check.Name]
+
+		switch {
+		case problem != "" && !wasFailing:
+			s.failingChecks[check.Name] = problem
+			log.Printf("[ControlPlane] Self-check %s failing: %s", check.Name, problem)
+			s.broadcast(selfCheckEvent(eventTypeSelfCheckFailed, check.Name, problem, now))
+			changed = append(changed, selfCheckNotification{Check: check.Name, Status: "failing", Message: problem, Time: now})
+		case problem != "":
+			s.failingChecks[check.Name] = problem
+		case wasFailing:
+			delete(s.failingChecks, check.Name)
+			log.Printf("[ControlPlane] Self-check %s recovered", check.Name)
+			s.broadcast(selfCheckEvent(eventTypeSelfCheckRecovered, check.Name, "", now))
+			changed = append(changed, selfCheckNotification{Check: check.Name, Status: "recovered", Time: now})
+		}
+	}
+	return changed
+}
+
+// failingSelfChecks lists the currently failing checks by name.
+// Callers must hold s.mu.
+func (s *ControlPlaneServer) failingSelfChecks() []string {
+	names := make([]string, 0, len(s.failingChecks))
+	for name := range s.failingChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func selfCheckEvent(eventType, check, message string, now time.Time) *pb.TraceEvent {
+	attrs := map[string]string{"check": check}
+	if message != "" {
+		attrs["message"] = message
+	}
+	return &pb.TraceEvent{
+		Timestamp:  now.Unix(),
+		EventType:  eventType,
+		Attributes: attrs,
+	}
+}
+
+func notifySelfCheck(url string, n selfCheckNotification) {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("[ControlPlane] Failed to send self-check notification: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("[ControlPlane] Self-check webhook returned %s", resp.Status)
+	}
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("[ControlPlane] Ignoring invalid %s=%q: %v", key, v, err)
+		return def
+	}
+	return d
+}
+
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("[ControlPlane] Ignoring invalid %s=%q: %v", key, v, err)
+		return def
+	}
+	return n
+}
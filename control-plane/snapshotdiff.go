@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DiffSnapshots lines up the variables of every snapshot of a trace, so one
+// can follow a value such as amount from the service that received it to
+// the one that charged it. Nested objects are flattened into dotted paths;
+// arrays are compared whole.
+func (s *ControlPlaneServer) DiffSnapshots(ctx context.Context, req *pb.DiffSnapshotsRequest) (*pb.DiffSnapshotsResponse, error) {
+	traceID := s.resolveTraceID(req.GetTraceId())
+	if !validTraceID.MatchString(traceID) {
+		return &pb.DiffSnapshotsResponse{
+			TraceId:     traceID,
+			Success:     false,
+			RespMessage: "a valid trace_id is required",
+		}, nil
+	}
+
+	snaps, err := s.snapshots.Get(traceID, "")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "loading snapshots: %v", err)
+	}
+	if len(snaps) == 0 {
+		return &pb.DiffSnapshotsResponse{
+			TraceId:     traceID,
+			Success:     false,
+			RespMessage: fmt.Sprintf("No snapshots for trace %s", traceID),
+		}, nil
+	}
+
+	resp := &pb.DiffSnapshotsResponse{TraceId: traceID}
+	flattened := make([]map[string]string, len(snaps))
+	names := make(map[string]bool)
+	for i, snap := range snaps {
+		meta := snap.proto()
+		meta.Data = ""
+		resp.Snapshots = append(resp.Snapshots, meta)
+
+		flattened[i] = flattenSnapshot(snap.Data)
+		for name := range flattened[i] {
+			if wantVariable(name, req.GetVariables()) {
+				names[name] = true
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	changed := 0
+	for _, name := range sorted {
+		diff := &pb.VariableDiff{Name: name}
+		for i, vars := range flattened {
+			value, ok := vars[name]
+			diff.Values = append(diff.Values, &pb.VariableValue{Present: ok, Value: value})
+			if i > 0 && (ok != diff.Values[0].Present || value != diff.Values[0].Value) {
+				diff.Changed = true
+			}
+		}
+		if diff.Changed {
+			changed++
+		} else if req.GetChangedOnly() {
+			continue
+		}
+		resp.Variables = append(resp.Variables, diff)
+	}
+
+	resp.Success = true
+	resp.RespMessage = fmt.Sprintf("%d variable(s) across %d snapshot(s), %d changed", len(sorted), len(snaps), changed)
+	return resp, nil
+}
+
+// wantVariable reports whether name was asked for, either by its own path
+// or by the path of an object it is in.
+func wantVariable(name string, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, w := range wanted {
+		if name == w || strings.HasPrefix(name, w+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenSnapshot maps dotted paths in snapshot data to their values as
+// compact JSON. Data that is not an object is reported as the single
+// variable "$".
+func flattenSnapshot(data string) map[string]string {
+	out := make(map[string]string)
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return out
+	}
+	if obj, ok := v.(map[string]interface{}); ok {
+		flattenValue("", obj, out)
+	} else {
+		out["$"] = compactJSON(v)
+	}
+	return out
+}
+
+func flattenValue(prefix string, obj map[string]interface{}, out map[string]string) {
+	for k, v := range obj {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenValue(name, nested, out)
+			continue
+		}
+		out[name] = compactJSON(v)
+	}
+}
+
+func compactJSON(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// snapshotGCInterval is how often the retention policy is applied.
+const snapshotGCInterval = 5 * time.Minute
+
+// snapshotRetention bounds what the snapshot store keeps. Zero fields are
+// not enforced.
+type snapshotRetention struct {
+	MaxAge  time.Duration
+	MaxSize int64 // total captured data, in bytes
+}
+
+func (r snapshotRetention) enabled() bool {
+	return r.MaxAge > 0 || r.MaxSize > 0
+}
+
+// snapshotRetentionFromEnv reads TRACERY_SNAPSHOT_RETENTION, a duration such
+// as 168h, and TRACERY_SNAPSHOT_MAX_SIZE, a size such as 10Gi.
+func snapshotRetentionFromEnv() (snapshotRetention, error) {
+	var r snapshotRetention
+	if v := os.Getenv("TRACERY_SNAPSHOT_RETENTION"); v != "" {
+		age, err := time.ParseDuration(v)
+		if err != nil || age <= 0 {
+			return r, fmt.Errorf("TRACERY_SNAPSHOT_RETENTION: invalid duration %q", v)
+		}
+		r.MaxAge = age
+	}
+	if v := os.Getenv("TRACERY_SNAPSHOT_MAX_SIZE"); v != "" {
+		size, err := parseByteSize(v)
+		if err != nil {
+			return r, fmt.Errorf("TRACERY_SNAPSHOT_MAX_SIZE: %v", err)
+		}
+		r.MaxSize = size
+	}
+	return r, nil
+}
+
+// runSnapshotGC applies the retention policy every interval. Each replica
+// collects its own store; purging a shared store twice is harmless.
+func (s *ControlPlaneServer) runSnapshotGC(retention snapshotRetention, interval time.Duration) {
+	log.Printf("[ControlPlane] Snapshot retention: max age %s, max size %d bytes", retention.MaxAge, retention.MaxSize)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		expired, oversize, err := s.collectSnapshots(retention, now)
+		if err != nil {
+			log.Printf("[ControlPlane] Snapshot GC failed: %v", err)
+		}
+		if expired+oversize > 0 {
+			log.Printf("[ControlPlane] Snapshot GC removed %d expired and %d snapshot(s) over the size limit", expired, oversize)
+		}
+	}
+}
+
+// collectSnapshots purges snapshots older than the maximum age, then
+// deletes the oldest remaining ones until their data fits the maximum size.
+func (s *ControlPlaneServer) collectSnapshots(retention snapshotRetention, now time.Time) (expired, oversize int, err error) {
+	if retention.MaxAge > 0 {
+		if expired, err = s.snapshots.Purge(now.Add(-retention.MaxAge)); err != nil {
+			return expired, 0, err
+		}
+	}
+	if retention.MaxSize <= 0 {
+		return expired, 0, nil
+	}
+
+	snaps, err := s.snapshots.List(SnapshotFilter{MetadataOnly: true})
+	if err != nil {
+		return expired, 0, err
+	}
+	total := int64(0)
+	for _, snap := range snaps {
+		total += snap.dataSize()
+	}
+	for _, snap := range snaps {
+		if total <= retention.MaxSize {
+			break
+		}
+		deleted, err := s.snapshots.Delete(snap.TraceID, snap.ID)
+		if err != nil {
+			return expired, oversize, err
+		}
+		if deleted {
+			oversize++
+		}
+		total -= snap.dataSize()
+	}
+	return expired, oversize, nil
+}
+
+// DeleteSnapshot removes one snapshot, for example one that captured data
+// it should not have.
+func (s *ControlPlaneServer) DeleteSnapshot(ctx context.Context, req *pb.DeleteSnapshotRequest) (*pb.DeleteSnapshotResponse, error) {
+	traceID := s.resolveTraceID(req.GetTraceId())
+	if !validTraceID.MatchString(traceID) || !validTraceID.MatchString(req.GetSnapshotId()) {
+		return &pb.DeleteSnapshotResponse{
+			Success:     false,
+			RespMessage: "a valid trace_id and snapshot_id are required",
+		}, nil
+	}
+
+	deleted, err := s.snapshots.Delete(traceID, req.GetSnapshotId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "deleting snapshot: %v", err)
+	}
+	if !deleted {
+		return &pb.DeleteSnapshotResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("No snapshot %s for trace %s", req.GetSnapshotId(), traceID),
+		}, nil
+	}
+
+	log.Printf("[ControlPlane] Deleted snapshot %s of trace %s", req.GetSnapshotId(), traceID)
+	return &pb.DeleteSnapshotResponse{
+		Success:     true,
+		RespMessage: fmt.Sprintf("Snapshot %s deleted", req.GetSnapshotId()),
+	}, nil
+}
@@ -0,0 +1,844 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// eventTypeSnapshotRecorded announces a stored snapshot. The event carries
+// the snapshot ID but not its data; WatchSnapshots streams the data.
+const eventTypeSnapshotRecorded = "snapshot_recorded"
+
+// Snapshot is the state an SDK captured when a request hit a breakpoint.
+type Snapshot struct {
+	ID           string    `json:"id"`
+	TraceID      string    `json:"trace_id"`
+	ServiceName  string    `json:"service_name"`
+	EndPoint     string    `json:"endpoint"`
+	BreakpointID string    `json:"breakpoint_id,omitempty"`
+	Checkpoint   string    `json:"checkpoint,omitempty"`
+	Data         string    `json:"data"`
+	Size         int64     `json:"size,omitempty"` // length of Data as captured
+	CapturedAt   time.Time `json:"captured_at"`
+
+	// BlobKey is where Data lives when a blob store holds it; the
+	// metadata store then keeps Data empty.
+	BlobKey string `json:"blob_key,omitempty"`
+}
+
+// SnapshotStore keeps snapshots keyed by trace. A trace has one snapshot per
+// service (and per hit) that captured it, so Get returns all of them.
+type SnapshotStore interface {
+	Save(snap *Snapshot) error
+	// Get returns the snapshots for a trace oldest first, optionally limited
+	// to one service.
+	Get(traceID, serviceName string) ([]*Snapshot, error)
+	// List returns up to filter.Limit snapshots matching filter, oldest
+	// first, starting after filter.After.
+	List(filter SnapshotFilter) ([]*Snapshot, error)
+	// Purge deletes snapshots captured before cutoff and returns how many
+	// were removed.
+	Purge(cutoff time.Time) (int, error)
+	// Delete removes one snapshot of a trace. It reports false when there
+	// is no such snapshot.
+	Delete(traceID, id string) (bool, error)
+}
+
+// SnapshotFilter selects snapshots for SnapshotStore.List. Empty fields
+// match everything.
+type SnapshotFilter struct {
+	TraceID     string
+	ServiceName string
+	Checkpoint  string
+	Since       time.Time // inclusive
+	Until       time.Time // exclusive
+
+	// After is the last snapshot of the previous page; List resumes with
+	// the one after it in (CapturedAt, ID) order.
+	After snapshotCursor
+	Limit int // zero means no limit
+
+	// MetadataOnly lets stores skip loading captured data; the snapshots
+	// come back with Data empty and Size set.
+	MetadataOnly bool
+}
+
+type snapshotCursor struct {
+	CapturedAt time.Time
+	ID         string
+}
+
+func (c snapshotCursor) before(snap *Snapshot) bool {
+	if !c.CapturedAt.Equal(snap.CapturedAt) {
+		return c.CapturedAt.Before(snap.CapturedAt)
+	}
+	return c.ID < snap.ID
+}
+
+// selectSnapshots applies filter to a store's candidates. Stores narrow the
+// candidates by trace themselves when they can.
+func selectSnapshots(snaps []*Snapshot, filter SnapshotFilter) []*Snapshot {
+	out := make([]*Snapshot, 0)
+	for _, snap := range snaps {
+		switch {
+		case filter.TraceID != "" && snap.TraceID != filter.TraceID,
+			filter.ServiceName != "" && snap.ServiceName != filter.ServiceName,
+			filter.Checkpoint != "" && snap.Checkpoint != filter.Checkpoint,
+			!filter.Since.IsZero() && snap.CapturedAt.Before(filter.Since),
+			!filter.Until.IsZero() && !snap.CapturedAt.Before(filter.Until),
+			!filter.After.CapturedAt.IsZero() && !filter.After.before(snap):
+			continue
+		}
+		out = append(out, snap)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return snapshotCursor{out[i].CapturedAt, out[i].ID}.before(out[j])
+	})
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+	return out
+}
+
+// newSnapshotStoreFromEnv persists snapshots under TRACERY_SNAPSHOT_DIR, or
+// keeps them in memory when it is unset. With TRACERY_SNAPSHOT_BLOB_URL set,
+// that store only keeps metadata and the captured data goes to the blob
+// store.
+func newSnapshotStoreFromEnv() (SnapshotStore, error) {
+	var store SnapshotStore
+	if dir := os.Getenv("TRACERY_SNAPSHOT_DIR"); dir != "" {
+		var err error
+		if store, err = NewFileSnapshotStore(dir); err != nil {
+			return nil, err
+		}
+	} else {
+		log.Printf("[ControlPlane] TRACERY_SNAPSHOT_DIR not set, snapshots are kept in memory only")
+		store = NewMemorySnapshotStore()
+	}
+
+	blobs, err := newBlobStoreFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if blobs == nil {
+		return store, nil
+	}
+	log.Printf("[ControlPlane] Storing snapshot data in %s", os.Getenv("TRACERY_SNAPSHOT_BLOB_URL"))
+	return NewBlobSnapshotStore(store, blobs), nil
+}
+
+type memorySnapshotStore struct {
+	mu      sync.RWMutex
+	byTrace map[string][]*Snapshot
+	order   []*Snapshot // in the order they were saved
+
+	bytes  int64 // estimated size of every snapshot
+	budget int64 // zero means unbounded
+	shed   int64 // snapshots dropped to stay within budget
+}
+
+func NewMemorySnapshotStore() SnapshotStore {
+	return &memorySnapshotStore{byTrace: make(map[string][]*Snapshot)}
+}
+
+func (m *memorySnapshotStore) Save(snap *Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byTrace[snap.TraceID] = append(m.byTrace[snap.TraceID], snap)
+	m.order = append(m.order, snap)
+	m.bytes += snap.size()
+
+	// Over budget, the oldest snapshots go first; the one just saved is
+	// always kept.
+	for m.budget > 0 && m.bytes > m.budget && len(m.order) > 1 {
+		m.remove(m.order[0])
+		m.order = m.order[1:]
+		m.shed++
+	}
+	return nil
+}
+
+// remove takes snap out of byTrace. Callers must hold m.mu and update
+// m.order themselves.
+func (m *memorySnapshotStore) remove(snap *Snapshot) {
+	m.bytes -= snap.size()
+	snaps := m.byTrace[snap.TraceID]
+	for i, s := range snaps {
+		if s == snap {
+			snaps = append(snaps[:i], snaps[i+1:]...)
+			break
+		}
+	}
+	if len(snaps) == 0 {
+		delete(m.byTrace, snap.TraceID)
+	} else {
+		m.byTrace[snap.TraceID] = snaps
+	}
+}
+
+// usage reports the store's estimated size, budget, snapshot count and
+// how many snapshots it has shed.
+func (m *memorySnapshotStore) usage() (bytes, budget, items, shed int64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.bytes, m.budget, int64(len(m.order)), m.shed
+}
+
+func (m *memorySnapshotStore) setBudget(budget int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budget = budget
+}
+
+// size estimates the memory a snapshot takes up.
+func (snap *Snapshot) size() int64 {
+	return int64(len(snap.ID)+len(snap.TraceID)+len(snap.ServiceName)+len(snap.EndPoint)+
+		len(snap.BreakpointID)+len(snap.Checkpoint)+len(snap.Data)+len(snap.BlobKey)) + memoryEntryOverhead
+}
+
+func (m *memorySnapshotStore) Get(traceID, serviceName string) ([]*Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return filterSnapshots(m.byTrace[traceID], serviceName), nil
+}
+
+func (m *memorySnapshotStore) List(filter SnapshotFilter) ([]*Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if filter.TraceID != "" {
+		return selectSnapshots(m.byTrace[filter.TraceID], filter), nil
+	}
+	var all []*Snapshot
+	for _, snaps := range m.byTrace {
+		all = append(all, snaps...)
+	}
+	return selectSnapshots(all, filter), nil
+}
+
+func (m *memorySnapshotStore) Purge(cutoff time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := 0
+	kept := m.order[:0]
+	for _, snap := range m.order {
+		if snap.CapturedAt.Before(cutoff) {
+			m.remove(snap)
+			purged++
+			continue
+		}
+		kept = append(kept, snap)
+	}
+	for i := len(kept); i < len(m.order); i++ {
+		m.order[i] = nil
+	}
+	m.order = kept
+	return purged, nil
+}
+
+func (m *memorySnapshotStore) Delete(traceID, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, snap := range m.byTrace[traceID] {
+		if snap.ID != id {
+			continue
+		}
+		m.remove(snap)
+		for i, s := range m.order {
+			if s == snap {
+				m.order = append(m.order[:i], m.order[i+1:]...)
+				break
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// fileSnapshotStore writes one JSON file per snapshot under a directory per
+// trace, so snapshots survive control-plane restarts. List works from an
+// in-memory index of snapshot metadata, built from the files on first use
+// and kept up to date by Save, Delete and Purge, so paging through
+// snapshots only reads the files whose data is asked for. The index
+// assumes this process is the only one writing to dir.
+type fileSnapshotStore struct {
+	dir string
+
+	mu    sync.Mutex
+	index map[string]map[string]*Snapshot // by trace ID, then snapshot ID; nil until built
+}
+
+func NewFileSnapshotStore(dir string) (SnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &fileSnapshotStore{dir: dir}, nil
+}
+
+func (f *fileSnapshotStore) Save(snap *Snapshot) error {
+	traceDir := filepath.Join(f.dir, snap.TraceID)
+	if err := os.MkdirAll(traceDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so a crash never leaves a half-written
+	// snapshot behind.
+	path := filepath.Join(traceDir, snap.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.index != nil {
+		f.indexSnapshot(snap)
+	}
+	return nil
+}
+
+// metadata returns a copy of snap without its data.
+func (snap *Snapshot) metadata() *Snapshot {
+	meta := *snap
+	meta.Size = snap.dataSize()
+	meta.Data = ""
+	return &meta
+}
+
+// indexSnapshot adds snap's metadata to the index. Callers must hold f.mu.
+func (f *fileSnapshotStore) indexSnapshot(snap *Snapshot) {
+	byID, ok := f.index[snap.TraceID]
+	if !ok {
+		byID = make(map[string]*Snapshot)
+		f.index[snap.TraceID] = byID
+	}
+	byID[snap.ID] = snap.metadata()
+}
+
+// unindexSnapshot drops a snapshot from the index. Callers must hold f.mu.
+func (f *fileSnapshotStore) unindexSnapshot(traceID, id string) {
+	if f.index == nil {
+		return
+	}
+	delete(f.index[traceID], id)
+	if len(f.index[traceID]) == 0 {
+		delete(f.index, traceID)
+	}
+}
+
+// buildIndex reads every snapshot file once. Callers must hold f.mu.
+func (f *fileSnapshotStore) buildIndex() error {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+	f.index = make(map[string]map[string]*Snapshot)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		snaps, err := f.load(entry.Name())
+		if err != nil {
+			f.index = nil
+			return err
+		}
+		for _, snap := range snaps {
+			f.indexSnapshot(snap)
+		}
+	}
+	return nil
+}
+
+func (f *fileSnapshotStore) Get(traceID, serviceName string) ([]*Snapshot, error) {
+	snaps, err := f.load(traceID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].CapturedAt.Before(snaps[j].CapturedAt)
+	})
+	return filterSnapshots(snaps, serviceName), nil
+}
+
+// List selects snapshots from the index and then reads the data of just
+// the selected ones, unless the filter asks for metadata only.
+func (f *fileSnapshotStore) List(filter SnapshotFilter) ([]*Snapshot, error) {
+	f.mu.Lock()
+	if f.index == nil {
+		if err := f.buildIndex(); err != nil {
+			f.mu.Unlock()
+			return nil, err
+		}
+	}
+	var candidates []*Snapshot
+	for traceID, byID := range f.index {
+		if filter.TraceID != "" && traceID != filter.TraceID {
+			continue
+		}
+		for _, meta := range byID {
+			candidates = append(candidates, meta)
+		}
+	}
+	selected := selectSnapshots(candidates, filter)
+	f.mu.Unlock()
+
+	out := make([]*Snapshot, 0, len(selected))
+	for _, meta := range selected {
+		if filter.MetadataOnly {
+			copied := *meta
+			out = append(out, &copied)
+			continue
+		}
+		snap, err := f.read(filepath.Join(f.dir, meta.TraceID, meta.ID+".json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // deleted since it was selected
+		}
+		if err != nil {
+			return nil, err
+		}
+		if snap != nil {
+			out = append(out, snap)
+		}
+	}
+	return out, nil
+}
+
+// load reads a trace's snapshots in no particular order.
+func (f *fileSnapshotStore) load(traceID string) ([]*Snapshot, error) {
+	paths, err := filepath.Glob(filepath.Join(f.dir, traceID, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	snaps := make([]*Snapshot, 0, len(paths))
+	for _, path := range paths {
+		snap, err := f.read(path)
+		if err != nil {
+			return nil, err
+		}
+		if snap != nil {
+			snaps = append(snaps, snap)
+		}
+	}
+	return snaps, nil
+}
+
+// read decodes one snapshot file. A corrupt file is logged and reads as nil.
+func (f *fileSnapshotStore) read(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		log.Printf("[ControlPlane] Skipping corrupt snapshot %s: %v", path, err)
+		return nil, nil
+	}
+	return &snap, nil
+}
+
+// Purge goes by file modification time, which is the capture time since
+// snapshot files are written once and never updated.
+func (f *fileSnapshotStore) Purge(cutoff time.Time) (int, error) {
+	traceDirs, err := os.ReadDir(f.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, traceDir := range traceDirs {
+		if !traceDir.IsDir() {
+			continue
+		}
+		dir := filepath.Join(f.dir, traceDir.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return purged, err
+		}
+
+		remaining := len(entries)
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return purged, err
+			}
+			f.mu.Lock()
+			f.unindexSnapshot(traceDir.Name(), strings.TrimSuffix(entry.Name(), ".json"))
+			f.mu.Unlock()
+			purged++
+			remaining--
+		}
+		if remaining == 0 {
+			os.Remove(dir)
+		}
+	}
+	return purged, nil
+}
+
+func (f *fileSnapshotStore) Delete(traceID, id string) (bool, error) {
+	dir := filepath.Join(f.dir, traceID)
+	err := os.Remove(filepath.Join(dir, id+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	os.Remove(dir) // only succeeds once the trace has no snapshots left
+
+	f.mu.Lock()
+	f.unindexSnapshot(traceID, id)
+	f.mu.Unlock()
+	return true, nil
+}
+
+// dataSize is the size of the captured data, which blob-backed metadata
+// no longer holds. Snapshots from before sizes were recorded fall back to
+// the data they carry.
+func (snap *Snapshot) dataSize() int64 {
+	if snap.Size > 0 {
+		return snap.Size
+	}
+	return int64(len(snap.Data))
+}
+
+func filterSnapshots(snaps []*Snapshot, serviceName string) []*Snapshot {
+	out := make([]*Snapshot, 0, len(snaps))
+	for _, snap := range snaps {
+		if serviceName == "" || snap.ServiceName == serviceName {
+			out = append(out, snap)
+		}
+	}
+	return out
+}
+
+// validTraceID guards the file store against path traversal; trace IDs are
+// hex in practice but we allow the usual ID characters.
+var validTraceID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// RecordSnapshot stores a snapshot posted by an SDK.
+func (s *ControlPlaneServer) RecordSnapshot(ctx context.Context, req *pb.RecordSnapshotRequest) (*pb.RecordSnapshotResponse, error) {
+	if !validTraceID.MatchString(req.GetTraceId()) {
+		return &pb.RecordSnapshotResponse{
+			Success:     false,
+			RespMessage: "a valid trace_id is required",
+		}, nil
+	}
+	if req.GetServiceName() == "" {
+		return &pb.RecordSnapshotResponse{
+			Success:     false,
+			RespMessage: "service_name is required",
+		}, nil
+	}
+	if !json.Valid([]byte(req.GetData())) {
+		return &pb.RecordSnapshotResponse{
+			Success:     false,
+			RespMessage: "data must be valid JSON",
+		}, nil
+	}
+
+	snap := &Snapshot{
+		ID:           uuid.New().String(),
+		TraceID:      req.GetTraceId(),
+		ServiceName:  req.GetServiceName(),
+		EndPoint:     req.GetEndpoint(),
+		BreakpointID: req.GetBreakpointId(),
+		Checkpoint:   req.GetCheckpoint(),
+		Data:         req.GetData(),
+		Size:         int64(len(req.GetData())),
+		CapturedAt:   time.Now(),
+	}
+	if err := s.snapshots.Save(snap); err != nil {
+		return nil, status.Errorf(codes.Internal, "saving snapshot: %v", err)
+	}
+
+	log.Printf("[ControlPlane] Stored snapshot %s for trace %s from %s%s", snap.ID, snap.TraceID, snap.ServiceName, snap.EndPoint)
+
+	attrs := map[string]string{"snapshot_id": snap.ID}
+	if snap.Checkpoint != "" {
+		attrs["checkpoint"] = snap.Checkpoint
+	}
+	s.mu.Lock()
+	s.broadcast(&pb.TraceEvent{
+		TraceId:      snap.TraceID,
+		ServiceName:  snap.ServiceName,
+		Endpoint:     snap.EndPoint,
+		Timestamp:    snap.CapturedAt.Unix(),
+		EventType:    eventTypeSnapshotRecorded,
+		BreakpointId: snap.BreakpointID,
+		Attributes:   attrs,
+	})
+	s.mu.Unlock()
+
+	return &pb.RecordSnapshotResponse{
+		SnapshotId:  snap.ID,
+		Success:     true,
+		RespMessage: "Snapshot stored",
+	}, nil
+}
+
+// PurgeSnapshots deletes snapshots older than the requested age. It is a
+// maintenance operation, so every run is logged with the caller.
+func (s *ControlPlaneServer) PurgeSnapshots(ctx context.Context, req *pb.PurgeSnapshotsRequest) (*pb.PurgeSnapshotsResponse, error) {
+	if req.GetOlderThanSeconds() <= 0 {
+		return &pb.PurgeSnapshotsResponse{
+			Success:     false,
+			RespMessage: "older_than_seconds must be positive",
+		}, nil
+	}
+
+	age := time.Duration(req.GetOlderThanSeconds()) * time.Second
+	purged, err := s.snapshots.Purge(time.Now().Add(-age))
+	caller := callerInfo(ctx)
+	log.Printf("[ControlPlane] Snapshot purge older than %s requested by %s (request %s): %d removed", age, caller, caller.ID, purged)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "purging snapshots after %d removed: %v", purged, err)
+	}
+
+	return &pb.PurgeSnapshotsResponse{
+		Purged:      int64(purged),
+		Success:     true,
+		RespMessage: fmt.Sprintf("Purged %d snapshot(s) older than %s", purged, age),
+	}, nil
+}
+
+const (
+	defaultSnapshotPageSize = 50
+	maxSnapshotPageSize     = 500
+)
+
+// ListSnapshots pages through stored snapshots, optionally narrowed to a
+// trace, service, checkpoint and capture-time range. Pages are keyed on the
+// last snapshot returned, so snapshots recorded while paging do not shift
+// later pages.
+func (s *ControlPlaneServer) ListSnapshots(ctx context.Context, req *pb.ListSnapshotsRequest) (*pb.ListSnapshotsResponse, error) {
+	filter := SnapshotFilter{
+		ServiceName:  req.GetServiceName(),
+		Checkpoint:   req.GetCheckpoint(),
+		MetadataOnly: !req.GetIncludeData(),
+	}
+	if req.GetTraceId() != "" {
+		filter.TraceID = s.resolveTraceID(req.GetTraceId())
+		if !validTraceID.MatchString(filter.TraceID) {
+			return &pb.ListSnapshotsResponse{
+				Success:     false,
+				RespMessage: "invalid trace_id",
+			}, nil
+		}
+	}
+	if req.GetSince() > 0 {
+		filter.Since = time.Unix(req.GetSince(), 0)
+	}
+	if req.GetUntil() > 0 {
+		filter.Until = time.Unix(req.GetUntil(), 0)
+	}
+
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0:
+		return &pb.ListSnapshotsResponse{
+			Success:     false,
+			RespMessage: "page_size must not be negative",
+		}, nil
+	case pageSize == 0:
+		pageSize = defaultSnapshotPageSize
+	case pageSize > maxSnapshotPageSize:
+		pageSize = maxSnapshotPageSize
+	}
+	// One extra tells whether there is another page.
+	filter.Limit = pageSize + 1
+
+	if req.GetPageToken() != "" {
+		cursor, err := parseSnapshotPageToken(req.GetPageToken())
+		if err != nil {
+			return &pb.ListSnapshotsResponse{
+				Success:     false,
+				RespMessage: "invalid page_token",
+			}, nil
+		}
+		filter.After = cursor
+	}
+
+	snaps, err := s.snapshots.List(filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "listing snapshots: %v", err)
+	}
+
+	resp := &pb.ListSnapshotsResponse{Success: true}
+	if len(snaps) > pageSize {
+		snaps = snaps[:pageSize]
+		last := snaps[len(snaps)-1]
+		resp.NextPageToken = snapshotPageToken(snapshotCursor{last.CapturedAt, last.ID})
+	}
+	for _, snap := range snaps {
+		p := snap.proto()
+		if !req.GetIncludeData() {
+			p.Data = ""
+		}
+		resp.Snapshots = append(resp.Snapshots, p)
+	}
+	resp.RespMessage = fmt.Sprintf("%d snapshot(s)", len(snaps))
+	return resp, nil
+}
+
+func snapshotPageToken(c snapshotCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.CapturedAt.UnixNano(), 10) + ":" + c.ID))
+}
+
+func parseSnapshotPageToken(token string) (snapshotCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return snapshotCursor{}, err
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return snapshotCursor{}, fmt.Errorf("malformed page token")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return snapshotCursor{}, err
+	}
+	return snapshotCursor{CapturedAt: time.Unix(0, n), ID: id}, nil
+}
+
+// GetSnapshot returns every snapshot captured for a trace. snapshot_data
+// holds the same snapshots as a JSON array for clients that just print it.
+func (s *ControlPlaneServer) GetSnapshot(ctx context.Context, req *pb.GetSnapshotRequest) (*pb.GetSnapshotResponse, error) {
+	traceID := s.resolveTraceID(req.GetTraceId())
+	if !validTraceID.MatchString(traceID) {
+		return &pb.GetSnapshotResponse{
+			TraceId:     traceID,
+			Success:     false,
+			RespMessage: "a valid trace_id is required",
+		}, nil
+	}
+
+	snaps, err := s.snapshots.Get(traceID, req.GetServiceName())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "loading snapshots: %v", err)
+	}
+	if len(snaps) == 0 {
+		return &pb.GetSnapshotResponse{
+			TraceId:     traceID,
+			Success:     false,
+			RespMessage: fmt.Sprintf("No snapshots for trace %s", traceID),
+		}, nil
+	}
+
+	data, err := json.MarshalIndent(snaps, "", "  ")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encoding snapshots: %v", err)
+	}
+
+	resp := &pb.GetSnapshotResponse{
+		TraceId:      traceID,
+		SnapshotData: string(data),
+		Success:      true,
+		RespMessage:  fmt.Sprintf("%d snapshot(s)", len(snaps)),
+	}
+	for _, snap := range snaps {
+		resp.Snapshots = append(resp.Snapshots, snap.proto())
+	}
+	return resp, nil
+}
+
+func (snap *Snapshot) proto() *pb.Snapshot {
+	return &pb.Snapshot{
+		Id:           snap.ID,
+		TraceId:      snap.TraceID,
+		ServiceName:  snap.ServiceName,
+		Endpoint:     snap.EndPoint,
+		BreakpointId: snap.BreakpointID,
+		Checkpoint:   snap.Checkpoint,
+		Data:         snap.Data,
+		CapturedAt:   snap.CapturedAt.Unix(),
+	}
+}
+
+// WatchSnapshots streams a trace's snapshots: the ones already stored, then
+// each new one as it is recorded, until the client goes away.
+func (s *ControlPlaneServer) WatchSnapshots(req *pb.WatchSnapshotsRequest, stream pb.ControlPlane_WatchSnapshotsServer) error {
+	traceID := s.resolveTraceID(req.GetTraceId())
+	if !validTraceID.MatchString(traceID) {
+		return status.Error(codes.InvalidArgument, "a valid trace_id is required")
+	}
+
+	// Subscribe before reading the store so nothing recorded in between
+	// is missed; sent guards against sending a snapshot twice. Snapshots
+	// are saved before they are announced and every event rereads the
+	// store, so an event dropped behind a full buffer loses nothing.
+	sub, unsubscribe, err := s.subscribe(defaultSubscriberClass, traceID, []string{eventTypeSnapshotRecorded})
+	if err != nil {
+		return err
+	}
+	defer unsubscribe()
+
+	sent := make(map[string]bool)
+	sendNew := func() error {
+		snaps, err := s.snapshots.Get(traceID, req.GetServiceName())
+		if err != nil {
+			return status.Errorf(codes.Internal, "loading snapshots: %v", err)
+		}
+		for _, snap := range snaps {
+			if sent[snap.ID] {
+				continue
+			}
+			if err := stream.Send(snap.proto()); err != nil {
+				return err
+			}
+			sent[snap.ID] = true
+		}
+		return nil
+	}
+	if err := sendNew(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, ok := <-sub.ch:
+			if !ok {
+				return sub.err
+			}
+			if req.GetServiceName() != "" && event.GetServiceName() != req.GetServiceName() {
+				continue
+			}
+			if err := sendNew(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSnapshotStoreList(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileSnapshotStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Now().Add(-time.Hour)
+	snaps := []*Snapshot{
+		{ID: "a1", TraceID: "trace-a", ServiceName: "order-processing", Data: `{"amount": 1500}`, CapturedAt: base},
+		{ID: "b1", TraceID: "trace-b", ServiceName: "payment", Data: `{"card": "visa"}`, CapturedAt: base.Add(time.Second)},
+	}
+	for _, snap := range snaps {
+		if err := store.Save(snap); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Build the index, then save one more so it has to be kept up to date.
+	if _, err := store.List(SnapshotFilter{MetadataOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	late := &Snapshot{ID: "a2", TraceID: "trace-a", ServiceName: "order-processing", Data: `{"amount": 20}`, CapturedAt: base.Add(2 * time.Second)}
+	if err := store.Save(late); err != nil {
+		t.Fatal(err)
+	}
+
+	// Metadata-only listing must not read the files, so a file clobbered
+	// behind the store's back still lists with the size it was saved with.
+	if err := os.WriteFile(filepath.Join(dir, "trace-b", "b1.json"), []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := store.List(SnapshotFilter{MetadataOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 3 {
+		t.Fatalf("listed %d snapshots, want 3", len(listed))
+	}
+	for i, want := range []*Snapshot{snaps[0], snaps[1], late} {
+		if got := listed[i]; got.ID != want.ID || got.Data != "" || got.Size != int64(len(want.Data)) {
+			t.Errorf("listed[%d] = %+v, want %s without data, size %d", i, got, want.ID, len(want.Data))
+		}
+	}
+
+	// A page with data reads just the selected files.
+	page, err := store.List(SnapshotFilter{TraceID: "trace-a", Limit: 1, After: snapshotCursor{base, "a1"}})
+	if err != nil || len(page) != 1 || page[0].ID != "a2" || page[0].Data != late.Data {
+		t.Errorf("second page of trace-a = %+v, %v; want a2 with its data", page, err)
+	}
+
+	if deleted, err := store.Delete("trace-a", "a1"); err != nil || !deleted {
+		t.Fatalf("Delete = %v, %v", deleted, err)
+	}
+	listed, err = store.List(SnapshotFilter{TraceID: "trace-a", MetadataOnly: true})
+	if err != nil || len(listed) != 1 || listed[0].ID != "a2" {
+		t.Errorf("after Delete, trace-a lists %+v, %v; want only a2", listed, err)
+	}
+
+	// A restarted control plane rebuilds the index from disk, skipping the
+	// corrupt file.
+	reopened, err := NewFileSnapshotStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed, err = reopened.List(SnapshotFilter{MetadataOnly: true})
+	if err != nil || len(listed) != 1 || listed[0].ID != "a2" {
+		t.Errorf("reopened store lists %+v, %v; want only a2", listed, err)
+	}
+}
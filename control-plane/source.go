@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// maxIndexedFiles caps how many source files are remembered per service.
+const maxIndexedFiles = 2000
+
+var (
+	// codeFileAttributes name the source file a span was started in; the
+	// second is the newer semantic convention.
+	codeFileAttributes = []string{"code.filepath", "code.file.path"}
+
+	// repoAttributes name the repository a service was built from, as
+	// resource attributes. tracery.build.module is what init-sdk reports
+	// from the binary's build info.
+	repoAttributes = []string{"vcs.repository.url.full", "tracery.build.module"}
+
+	// versionAttributes identify the deployed build, as resource attributes.
+	versionAttributes = []string{"service.version", "vcs.ref.head.revision"}
+)
+
+// serviceCode is what a service's spans have said about its code: the build
+// currently deployed and the source files spans were started in.
+type serviceCode struct {
+	Version string
+	Repo    string
+	Files   map[string]bool // as reported, usually absolute build paths
+}
+
+// indexCode records the build and source file a span reports. A new version
+// starts a fresh file list, since files may have moved. Callers must hold
+// s.mu.
+func (s *ControlPlaneServer) indexCode(service string, resource, attrs pcommon.Map) {
+	version := firstAttribute(resource, versionAttributes)
+	code, ok := s.codeIndex[service]
+	if !ok || (version != "" && version != code.Version) {
+		code = &serviceCode{Version: version, Files: make(map[string]bool)}
+		s.codeIndex[service] = code
+	}
+	if repo := firstAttribute(resource, repoAttributes); repo != "" {
+		code.Repo = repo
+	}
+	if file := firstAttribute(attrs, codeFileAttributes); file != "" && len(code.Files) < maxIndexedFiles {
+		code.Files[file] = true
+	}
+}
+
+func firstAttribute(m pcommon.Map, keys []string) string {
+	for _, key := range keys {
+		if v, ok := m.Get(key); ok && v.AsString() != "" {
+			return v.AsString()
+		}
+	}
+	return ""
+}
+
+// resolveSource checks a breakpoint's source location against what the
+// service's spans report and fills in the deployed version. It fails when the
+// location plainly does not belong to the service; a file that no span has
+// reported yet is accepted but left unverified, since most spans carry no
+// code attributes. Callers must hold s.mu.
+func (s *ControlPlaneServer) resolveSource(service string, loc *pb.SourceLocation) (*pb.SourceLocation, error) {
+	if loc == nil || (loc.GetFile() == "" && loc.GetRepo() == "") {
+		return nil, nil
+	}
+	if loc.GetFile() == "" {
+		return nil, fmt.Errorf("source location needs a file")
+	}
+	if loc.GetLine() < 0 {
+		return nil, fmt.Errorf("source line must not be negative")
+	}
+
+	resolved := &pb.SourceLocation{Repo: loc.GetRepo(), File: path.Clean(loc.GetFile()), Line: loc.GetLine()}
+	code, ok := s.codeIndex[service]
+	if !ok {
+		return resolved, nil
+	}
+	resolved.Version = code.Version
+
+	if resolved.Repo != "" && code.Repo != "" && normalizeRepo(resolved.Repo) != normalizeRepo(code.Repo) {
+		return nil, fmt.Errorf("%s is built from %s, not %s", service, code.Repo, resolved.Repo)
+	}
+	for file := range code.Files {
+		if file == resolved.File || strings.HasSuffix(file, "/"+resolved.File) {
+			resolved.Verified = true
+			break
+		}
+	}
+	return resolved, nil
+}
+
+// normalizeRepo lets https://github.com/org/repo.git, github.com/org/repo and
+// a module path inside that repo compare equal on their first three path
+// elements.
+func normalizeRepo(repo string) string {
+	if i := strings.Index(repo, "://"); i >= 0 {
+		repo = repo[i+3:]
+	}
+	repo = strings.TrimSuffix(strings.TrimSuffix(repo, "/"), ".git")
+	if parts := strings.SplitN(repo, "/", 4); len(parts) == 4 {
+		repo = strings.Join(parts[:3], "/")
+	}
+	return strings.ToLower(repo)
+}
+
+// sourceString renders a location as "repo file:line" for logs and messages.
+func sourceString(loc *pb.SourceLocation) string {
+	s := loc.GetFile()
+	if loc.GetRepo() != "" {
+		s = strings.TrimSuffix(loc.GetRepo(), "/") + " " + s
+	}
+	if loc.GetLine() > 0 {
+		s += fmt.Sprintf(":%d", loc.GetLine())
+	}
+	return s
+}
+
+// sourceNote tells the person registering a breakpoint how far its source
+// location could be checked.
+func sourceNote(service string, loc *pb.SourceLocation) string {
+	switch {
+	case loc == nil:
+		return ""
+	case loc.GetVerified():
+		return fmt.Sprintf(" (source %s seen in %s %s)", sourceString(loc), service, loc.GetVersion())
+	case loc.GetVersion() != "":
+		return fmt.Sprintf(" (source %s not yet seen in spans of %s %s)", sourceString(loc), service, loc.GetVersion())
+	}
+	return fmt.Sprintf(" (source %s unverified: %s has not reported its build)", sourceString(loc), service)
+}
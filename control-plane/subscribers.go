@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync/atomic"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// What broadcast does when a subscriber's buffer is full.
+const (
+	dropNewest = "drop_newest" // discard the incoming event
+	dropOldest = "drop_oldest" // discard the oldest buffered event to make room
+	disconnect = "disconnect"  // end the subscriber's stream so it can resync
+)
+
+const (
+	defaultSubscriberClass = "interactive"
+	maxSubscriberBuffer    = 100000
+)
+
+// subscriberClass is the delivery policy shared by a kind of stream
+// consumer. Buffer size applies to subscribers that join after a change;
+// the drop policy applies to everyone immediately.
+type subscriberClass struct {
+	Name       string
+	BufferSize int
+	DropPolicy string
+	Dropped    atomic.Int64 // updated by concurrent broadcast workers
+}
+
+// defaultSubscriberClasses covers the consumers we know about: people at a
+// terminal want the stream to stay responsive, dashboards care about recent
+// state, and archival consumers would rather reconnect than keep a gap.
+func defaultSubscriberClasses() map[string]*subscriberClass {
+	return map[string]*subscriberClass{
+		"interactive": {Name: "interactive", BufferSize: 100, DropPolicy: dropNewest},
+		"dashboard":   {Name: "dashboard", BufferSize: 1000, DropPolicy: dropOldest},
+		"archival":    {Name: "archival", BufferSize: 10000, DropPolicy: disconnect},
+	}
+}
+
+// subscriber is one open event stream.
+type subscriber struct {
+	ch         chan *pb.TraceEvent
+	class      *subscriberClass
+	traceID    string          // empty means every trace
+	eventTypes map[string]bool // empty means every event type
+	err        error           // why the control plane closed ch, if it did
+	dropped    atomic.Int64    // events this subscriber lost to a full buffer
+}
+
+// wants filters before delivery, so events for other traces never take up
+// room in a per-trace subscriber's buffer.
+func (sub *subscriber) wants(event *pb.TraceEvent) bool {
+	if sub.traceID != "" && event.GetTraceId() != sub.traceID {
+		return false
+	}
+	return len(sub.eventTypes) == 0 || sub.eventTypes[event.GetEventType()]
+}
+
+// deliver hands an event to a subscriber according to its class policy. It
+// returns false when the subscriber has to be disconnected.
+func (sub *subscriber) deliver(event *pb.TraceEvent) bool {
+	select {
+	case sub.ch <- event:
+		return true
+	default:
+	}
+
+	sub.class.Dropped.Add(1)
+	sub.dropped.Add(1)
+	switch sub.class.DropPolicy {
+	case dropOldest:
+		select {
+		case <-sub.ch:
+		default:
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	case disconnect:
+		return false
+	}
+	return true
+}
+
+// laggedError is returned to a subscriber disconnected for falling behind.
+func laggedError() error {
+	return status.Error(codes.ResourceExhausted, "subscriber fell behind and was disconnected; reconnect to resume")
+}
+
+func validDropPolicy(policy string) bool {
+	return policy == dropNewest || policy == dropOldest || policy == disconnect
+}
+
+// SetSubscriberClass creates or adjusts a subscriber class at runtime.
+func (s *ControlPlaneServer) SetSubscriberClass(ctx context.Context, req *pb.SetSubscriberClassRequest) (*pb.SetSubscriberClassResponse, error) {
+	if req.GetName() == "" {
+		return &pb.SetSubscriberClassResponse{
+			Success:     false,
+			RespMessage: "name is required",
+		}, nil
+	}
+	if req.GetBufferSize() < 0 || req.GetBufferSize() > maxSubscriberBuffer {
+		return &pb.SetSubscriberClassResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("buffer_size must be between 1 and %d, or 0 to keep the current size", maxSubscriberBuffer),
+		}, nil
+	}
+	if req.GetDropPolicy() != "" && !validDropPolicy(req.GetDropPolicy()) {
+		return &pb.SetSubscriberClassResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("drop_policy must be %s, %s or %s", dropNewest, dropOldest, disconnect),
+		}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	class, ok := s.subscriberClasses[req.GetName()]
+	if !ok {
+		class = &subscriberClass{Name: req.GetName(), BufferSize: 100, DropPolicy: dropNewest}
+		s.subscriberClasses[req.GetName()] = class
+	}
+	if req.GetBufferSize() > 0 {
+		class.BufferSize = int(req.GetBufferSize())
+	}
+	if req.GetDropPolicy() != "" {
+		class.DropPolicy = req.GetDropPolicy()
+	}
+
+	log.Printf("[ControlPlane] Subscriber class %s: buffer %d, %s", class.Name, class.BufferSize, class.DropPolicy)
+
+	return &pb.SetSubscriberClassResponse{
+		Success:     true,
+		RespMessage: fmt.Sprintf("Subscriber class %s: buffer %d, %s", class.Name, class.BufferSize, class.DropPolicy),
+	}, nil
+}
+
+func (s *ControlPlaneServer) ListSubscriberClasses(ctx context.Context, req *pb.ListSubscriberClassesRequest) (*pb.ListSubscriberClassesResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[*subscriberClass]int32)
+	for _, sub := range s.traceListeners {
+		counts[sub.class]++
+	}
+
+	classes := make([]*pb.SubscriberClass, 0, len(s.subscriberClasses))
+	for _, class := range s.subscriberClasses {
+		classes = append(classes, &pb.SubscriberClass{
+			Name:        class.Name,
+			BufferSize:  int32(class.BufferSize),
+			DropPolicy:  class.DropPolicy,
+			Subscribers: counts[class],
+			Dropped:     class.Dropped.Load(),
+		})
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+
+	return &pb.ListSubscriberClassesResponse{Classes: classes}, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+)
+
+func TestTraceSubscriberOnlyBuffersItsTrace(t *testing.T) {
+	s := NewControlPlaneServer(nil, nil)
+	sub, unsubscribe, err := s.subscribe(defaultSubscriberClass, "trace-a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	buffer := cap(sub.ch)
+	s.mu.Lock()
+	for i := 0; i < 2*buffer; i++ {
+		s.broadcast(&pb.TraceEvent{TraceId: "trace-b", EventType: eventTypeSpan})
+	}
+	s.mu.Unlock()
+	if len(sub.ch) != 0 || sub.dropped.Load() != 0 {
+		t.Fatalf("other traces reached the subscriber: %d buffered, %d dropped", len(sub.ch), sub.dropped.Load())
+	}
+
+	s.mu.Lock()
+	for i := 0; i < buffer+3; i++ {
+		s.broadcast(&pb.TraceEvent{TraceId: "trace-a", EventType: eventTypeSpan})
+	}
+	s.mu.Unlock()
+	if got := sub.dropped.Load(); got != 3 {
+		t.Errorf("dropped = %d, want 3", got)
+	}
+}
+
+func TestFanoutMatchesInlineDelivery(t *testing.T) {
+	s := NewControlPlaneServer(nil, nil)
+	s.fanout = newFanout(4)
+
+	var subs []*subscriber
+	for i := 0; i < 500; i++ {
+		class := defaultSubscriberClass
+		if i%5 == 0 {
+			class = "archival"
+		}
+		sub, unsubscribe, err := s.subscribe(class, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unsubscribe()
+		subs = append(subs, sub)
+	}
+	// Fill the archival buffers so the next event disconnects them.
+	filler := &pb.TraceEvent{}
+	s.mu.Lock()
+	for _, sub := range subs {
+		for len(sub.ch) < cap(sub.ch) {
+			sub.ch <- filler
+		}
+	}
+	s.broadcast(&pb.TraceEvent{TraceId: "trace-a", EventType: eventTypeSpan})
+	listeners := len(s.traceListeners)
+	s.mu.Unlock()
+
+	if listeners != 400 {
+		t.Errorf("%d listeners left, want the 400 interactive ones", listeners)
+	}
+	if got := s.subscriberClasses[defaultSubscriberClass].Dropped.Load(); got != 400 {
+		t.Errorf("interactive class dropped %d events, want 400", got)
+	}
+	for i, sub := range subs {
+		if archival := i%5 == 0; archival != (sub.err != nil) {
+			t.Fatalf("subscriber %d: err = %v", i, sub.err)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/controlplane/proto/controlplane"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxStoredTraces and maxSpansPerTrace bound the trace store's memory.
+	// The oldest trace is evicted first; spans past the limit are dropped.
+	maxStoredTraces  = 10000
+	maxSpansPerTrace = 1000
+
+	// traceRetention is how long a trace is kept after its first span.
+	traceRetention = 15 * time.Minute
+)
+
+type storedSpan struct {
+	ServiceName string
+	Endpoint    string
+	Attributes  map[string]string
+	Span        *pb.Span
+}
+
+type assembledTrace struct {
+	Spans     map[string]*storedSpan // by span ID
+	Hits      map[string]bool        // breakpoints that already hit the trace
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Bytes     int64 // estimated size of Spans
+}
+
+// traceStore assembles spans received over OTLP into whole traces so they
+// can be looked at after the fact. It is guarded by ControlPlaneServer.mu.
+type traceStore struct {
+	traces map[string]*assembledTrace
+	order  []string // trace IDs by first span, oldest first
+
+	bytes  int64 // estimated size of every stored span
+	budget int64 // zero means only the count limits apply
+	shed   int64 // traces evicted to stay within budget
+}
+
+func newTraceStore() *traceStore {
+	return &traceStore{traces: make(map[string]*assembledTrace)}
+}
+
+// add files a span under its trace. A span received again, as happens when
+// an exporter retries, replaces the earlier copy.
+func (t *traceStore) add(traceID string, span *storedSpan, now time.Time) {
+	t.evict(now)
+
+	trace, ok := t.traces[traceID]
+	if !ok {
+		for len(t.order) >= maxStoredTraces {
+			t.evictOldest()
+		}
+		trace = &assembledTrace{Spans: make(map[string]*storedSpan), Hits: make(map[string]bool), FirstSeen: now}
+		t.traces[traceID] = trace
+		t.order = append(t.order, traceID)
+	}
+	old, seen := trace.Spans[span.Span.GetSpanId()]
+	if !seen && len(trace.Spans) >= maxSpansPerTrace {
+		return
+	}
+	size := span.size()
+	if seen {
+		size -= old.size()
+	}
+	trace.Spans[span.Span.GetSpanId()] = span
+	trace.LastSeen = now
+	trace.Bytes += size
+	t.bytes += size
+
+	t.shedOverBudget()
+}
+
+// evict drops traces whose first span is older than traceRetention.
+func (t *traceStore) evict(now time.Time) {
+	for len(t.order) > 0 && now.Sub(t.traces[t.order[0]].FirstSeen) > traceRetention {
+		t.evictOldest()
+	}
+}
+
+func (t *traceStore) evictOldest() {
+	t.evictAt(0)
+}
+
+func (t *traceStore) evictAt(i int) {
+	t.bytes -= t.traces[t.order[i]].Bytes
+	delete(t.traces, t.order[i])
+	if i == 0 {
+		t.order = t.order[1:]
+	} else {
+		t.order = append(t.order[:i], t.order[i+1:]...)
+	}
+}
+
+// shedOverBudget evicts traces until the store is within its memory budget.
+// Traces no breakpoint has hit go first, oldest first; those that were hit
+// are what people come looking for, so they only go once nothing else is
+// left.
+func (t *traceStore) shedOverBudget() {
+	if t.budget <= 0 {
+		return
+	}
+	next := 0
+	for t.bytes > t.budget && len(t.order) > 0 {
+		for next < len(t.order) && len(t.traces[t.order[next]].Hits) > 0 {
+			next++
+		}
+		if next >= len(t.order) {
+			t.evictOldest()
+		} else {
+			t.evictAt(next)
+		}
+		t.shed++
+	}
+}
+
+// size estimates the memory a stored span takes up.
+func (span *storedSpan) size() int64 {
+	n := int64(proto.Size(span.Span)) + int64(len(span.ServiceName)+len(span.Endpoint)) + memoryEntryOverhead
+	for k, v := range span.Attributes {
+		n += int64(len(k)+len(v)) + memoryEntryOverhead
+	}
+	return n
+}
+
+// serviceTarget merges every span the trace has from req's service with req
+// itself. Where spans disagree on an attribute the latest-starting span
+// wins, and req wins over all of them.
+func (t *traceStore) serviceTarget(req *pb.CheckBreakpointRequest) *matchTarget {
+	target := &matchTarget{
+		ServiceName: req.GetServiceName(),
+		Endpoints:   []string{req.GetEndpoint()},
+		Attributes:  make(map[string]string),
+	}
+
+	if trace, ok := t.traces[req.GetTraceId()]; ok {
+		var spans []*storedSpan
+		for _, span := range trace.Spans {
+			if span.ServiceName == req.GetServiceName() {
+				spans = append(spans, span)
+			}
+		}
+		sort.Slice(spans, func(i, j int) bool {
+			return spans[i].Span.GetStartTimeUnixNano() < spans[j].Span.GetStartTimeUnixNano()
+		})
+		for _, span := range spans {
+			target.Endpoints = append(target.Endpoints, span.Endpoint)
+			for k, v := range span.Attributes {
+				target.Attributes[k] = v
+			}
+		}
+	}
+
+	for k, v := range req.GetAttributes() {
+		target.Attributes[k] = v
+	}
+	return target
+}
+
+// hit reports whether breakpointID has already hit the trace.
+func (t *traceStore) hit(traceID, breakpointID string) bool {
+	trace, ok := t.traces[traceID]
+	return ok && trace.Hits[breakpointID]
+}
+
+// markHit records that breakpointID hit the trace. Traces the store has no
+// spans for are not tracked.
+func (t *traceStore) markHit(traceID, breakpointID string) {
+	if trace, ok := t.traces[traceID]; ok {
+		trace.Hits[breakpointID] = true
+	}
+}
+
+// complete guesses whether a trace has finished: there is a root span,
+// every parent has arrived and nothing new has come in for idleTimeout.
+func (trace *assembledTrace) complete(now time.Time, idleTimeout time.Duration) bool {
+	if now.Sub(trace.LastSeen) < idleTimeout {
+		return false
+	}
+	root := false
+	for _, span := range trace.Spans {
+		parent := span.Span.GetParentSpanId()
+		if parent == "" {
+			root = true
+			continue
+		}
+		if _, ok := trace.Spans[parent]; !ok {
+			return false
+		}
+	}
+	return root
+}
+
+// tree links spans to their parents. Spans whose parent is missing are
+// returned as roots alongside the real root.
+func (trace *assembledTrace) tree() []*pb.TraceSpan {
+	nodes := make(map[string]*pb.TraceSpan, len(trace.Spans))
+	for id, span := range trace.Spans {
+		nodes[id] = &pb.TraceSpan{
+			ServiceName: span.ServiceName,
+			Endpoint:    span.Endpoint,
+			Attributes:  span.Attributes,
+			Span:        span.Span,
+		}
+	}
+
+	var roots []*pb.TraceSpan
+	for id, node := range nodes {
+		if parent, ok := nodes[node.Span.GetParentSpanId()]; ok && !trace.descendsFrom(node.Span.GetParentSpanId(), id) {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	for _, node := range nodes {
+		sortTraceSpans(node.Children)
+	}
+	sortTraceSpans(roots)
+	return roots
+}
+
+// descendsFrom reports whether following parents up from spanID reaches
+// ancestorID. A misbehaving exporter can send spans that are each other's
+// parent; such a span is made a root rather than closing the loop.
+func (trace *assembledTrace) descendsFrom(spanID, ancestorID string) bool {
+	for i := 0; i <= len(trace.Spans); i++ {
+		if spanID == ancestorID {
+			return true
+		}
+		span, ok := trace.Spans[spanID]
+		if !ok {
+			return false
+		}
+		spanID = span.Span.GetParentSpanId()
+	}
+	return true
+}
+
+func sortTraceSpans(spans []*pb.TraceSpan) {
+	sort.Slice(spans, func(i, j int) bool {
+		a, b := spans[i].Span, spans[j].Span
+		if a.GetStartTimeUnixNano() != b.GetStartTimeUnixNano() {
+			return a.GetStartTimeUnixNano() < b.GetStartTimeUnixNano()
+		}
+		return a.GetSpanId() < b.GetSpanId()
+	})
+}
+
+// GetTrace returns the span tree of a trace received over OTLP in the last
+// traceRetention.
+func (s *ControlPlaneServer) GetTrace(ctx context.Context, req *pb.GetTraceRequest) (*pb.GetTraceResponse, error) {
+	traceID := s.resolveTraceID(req.GetTraceId())
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	trace, ok := s.traces.traces[traceID]
+	if !ok {
+		return &pb.GetTraceResponse{
+			Success:     false,
+			RespMessage: fmt.Sprintf("No spans received for trace %s", traceID),
+			TraceId:     traceID,
+		}, nil
+	}
+
+	complete := trace.complete(time.Now(), defaultTraceIdleTimeout)
+	state := "in progress"
+	if complete {
+		state = "complete"
+	}
+	return &pb.GetTraceResponse{
+		Success:     true,
+		RespMessage: fmt.Sprintf("%d span(s), %s", len(trace.Spans), state),
+		TraceId:     traceID,
+		Complete:    complete,
+		SpanCount:   int32(len(trace.Spans)),
+		Roots:       trace.tree(),
+		FirstSeen:   trace.FirstSeen.Unix(),
+		LastSeen:    trace.LastSeen.Unix(),
+	}, nil
+}
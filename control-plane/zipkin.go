@@ -0,0 +1,199 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// maxZipkinBytes caps one Zipkin upload, after decompression.
+const maxZipkinBytes = 5 << 20
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int64  `json:"port"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"` // microseconds
+	Value     string `json:"value"`
+}
+
+// zipkinSpan is a span in Zipkin's v2 JSON format.
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ID             string             `json:"id"`
+	ParentID       string             `json:"parentId"`
+	Name           string             `json:"name"`
+	Kind           string             `json:"kind"`
+	Timestamp      uint64             `json:"timestamp"` // microseconds
+	Duration       uint64             `json:"duration"`  // microseconds
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint"`
+	Annotations    []zipkinAnnotation `json:"annotations"`
+	Tags           map[string]string  `json:"tags"`
+}
+
+// handleZipkinSpans implements Zipkin's POST /api/v2/spans for JSON
+// uploads, so services using Zipkin or B3 libraries can drive breakpoints.
+// Spans are translated to OTLP and go through the same path as OTLP
+// exports.
+func (s *ControlPlaneServer) handleZipkinSpans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/json") {
+		http.Error(w, "only application/json is supported", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "invalid gzip body", http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxZipkinBytes+1))
+	if err != nil {
+		http.Error(w, "unreadable body", http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxZipkinBytes {
+		http.Error(w, "upload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var spans []zipkinSpan
+	if err := json.Unmarshal(data, &spans); err != nil {
+		http.Error(w, "invalid Zipkin v2 JSON", http.StatusBadRequest)
+		return
+	}
+	traces, err := zipkinSpansToTraces(spans)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.ingestTraces(traces)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// zipkinSpansToTraces converts Zipkin spans the way the OpenTelemetry
+// collector's Zipkin receiver does: the local endpoint names the service,
+// annotations become span events, and an error tag marks the span failed.
+func zipkinSpansToTraces(spans []zipkinSpan) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	for _, zs := range spans {
+		traceID, err := zipkinTraceID(zs.TraceID)
+		if err != nil {
+			return traces, err
+		}
+		spanID, err := zipkinSpanID(zs.ID)
+		if err != nil {
+			return traces, err
+		}
+
+		rs := traces.ResourceSpans().AppendEmpty()
+		service := "unknown"
+		if zs.LocalEndpoint != nil && zs.LocalEndpoint.ServiceName != "" {
+			service = zs.LocalEndpoint.ServiceName
+		}
+		rs.Resource().Attributes().PutStr("service.name", service)
+
+		span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetTraceID(traceID)
+		span.SetSpanID(spanID)
+		if zs.ParentID != "" {
+			parentID, err := zipkinSpanID(zs.ParentID)
+			if err != nil {
+				return traces, err
+			}
+			span.SetParentSpanID(parentID)
+		}
+		span.SetName(zs.Name)
+		span.SetKind(zipkinSpanKind(zs.Kind))
+		span.SetStartTimestamp(pcommon.Timestamp(zs.Timestamp * 1000))
+		span.SetEndTimestamp(pcommon.Timestamp((zs.Timestamp + zs.Duration) * 1000))
+
+		attrs := span.Attributes()
+		for k, v := range zs.Tags {
+			if k == "error" {
+				span.Status().SetCode(ptrace.StatusCodeError)
+				span.Status().SetMessage(v)
+				continue
+			}
+			attrs.PutStr(k, v)
+		}
+		if remote := zs.RemoteEndpoint; remote != nil {
+			if remote.ServiceName != "" {
+				attrs.PutStr("peer.service", remote.ServiceName)
+			}
+			if remote.IPv4 != "" {
+				attrs.PutStr("net.peer.ip", remote.IPv4)
+			} else if remote.IPv6 != "" {
+				attrs.PutStr("net.peer.ip", remote.IPv6)
+			}
+			if remote.Port != 0 {
+				attrs.PutInt("net.peer.port", remote.Port)
+			}
+		}
+
+		for _, a := range zs.Annotations {
+			event := span.Events().AppendEmpty()
+			event.SetTimestamp(pcommon.Timestamp(a.Timestamp * 1000))
+			event.SetName(a.Value)
+		}
+	}
+	return traces, nil
+}
+
+// zipkinTraceID accepts 64- and 128-bit hex trace IDs; 64-bit IDs fill the
+// low half, as in B3.
+func zipkinTraceID(s string) (pcommon.TraceID, error) {
+	var id pcommon.TraceID
+	b, err := hex.DecodeString(s)
+	if err != nil || (len(b) != 8 && len(b) != 16) {
+		return id, fmt.Errorf("invalid traceId %q", s)
+	}
+	copy(id[len(id)-len(b):], b)
+	return id, nil
+}
+
+func zipkinSpanID(s string) (pcommon.SpanID, error) {
+	var id pcommon.SpanID
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(id) {
+		return id, fmt.Errorf("invalid span id %q", s)
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+func zipkinSpanKind(kind string) ptrace.SpanKind {
+	switch kind {
+	case "SERVER":
+		return ptrace.SpanKindServer
+	case "CLIENT":
+		return ptrace.SpanKindClient
+	case "PRODUCER":
+		return ptrace.SpanKindProducer
+	case "CONSUMER":
+		return ptrace.SpanKindConsumer
+	}
+	return ptrace.SpanKindUnspecified
+}
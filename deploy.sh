@@ -18,6 +18,17 @@ echo "Deploying OpenTelemetry Collector..."
 kubectl apply -f k8s/otel-collector.yaml
 kubectl wait --for=condition=ready pod -l app=otel-collector --timeout=60s
 
+# Optionally route spans to the control plane through Kafka
+if [ "${TRACERY_KAFKA:-}" = "1" ]; then
+    echo ""
+    echo "Deploying Kafka span pipeline..."
+    kubectl apply -f k8s/kafka.yaml
+    kubectl wait --for=condition=ready pod -l app=kafka --timeout=120s
+    kubectl apply -f k8s/otel-collector-kafka.yaml
+    kubectl rollout status deployment/otel-collector --timeout=120s
+    kubectl rollout status deployment/tracery-span-consumer --timeout=120s
+fi
+
 # Deploy Jaeger
 echo ""
 echo "Deploying Jaeger..."
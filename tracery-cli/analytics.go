@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+// heatmapShades go from no hits to the busiest hour in the window.
+var heatmapShades = []rune{'·', '░', '▒', '▓', '█'}
+
+// showAnalytics prints a heatmap of breakpoint hits per endpoint per hour
+// followed by the attribute values that matched most often.
+func showAnalytics(ctx context.Context, client pb.ControlPlaneClient, breakpoint string, hours int64, top int32) {
+	resp, err := client.GetBreakpointAnalytics(ctx, &pb.GetBreakpointAnalyticsRequest{
+		BreakpointId: breakpoint,
+		Hours:        hours,
+		TopValues:    top,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if !resp.Success {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+		return
+	}
+
+	fmt.Printf("Breakpoint hits: %s\n", resp.RespMessage)
+	if resp.TotalHits == 0 {
+		return
+	}
+
+	// One row per endpoint, one column per hour, oldest on the left.
+	end := time.Now().Truncate(time.Hour).Unix()
+	start := end - (hours-1)*3600
+	rows := make(map[string][]int64)
+	var peak int64
+	for _, h := range resp.Hourly {
+		row := h.ServiceName + h.Endpoint
+		if rows[row] == nil {
+			rows[row] = make([]int64, hours)
+		}
+		col := (h.Hour - start) / 3600
+		if col < 0 || col >= hours {
+			continue
+		}
+		rows[row][col] += h.Hits
+		if rows[row][col] > peak {
+			peak = rows[row][col]
+		}
+	}
+
+	names := make([]string, 0, len(rows))
+	width := 0
+	for name := range rows {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Printf("\n%-*s  %s .. %s (peak %d/hour)\n", width, "", time.Unix(start, 0).Format("Jan 02 15:00"), time.Unix(end, 0).Format("Jan 02 15:00"), peak)
+	for _, name := range names {
+		var cells strings.Builder
+		var total int64
+		for _, n := range rows[name] {
+			cells.WriteRune(heatmapShade(n, peak))
+			total += n
+		}
+		fmt.Printf("%-*s  %s %d\n", width, name, cells.String(), total)
+	}
+
+	if len(resp.TopValues) > 0 {
+		fmt.Println("\nTop matching attribute values:")
+		for _, v := range resp.TopValues {
+			fmt.Printf("  %6d  %s=%s\n", v.Hits, v.Key, v.Value)
+		}
+	}
+}
+
+func heatmapShade(n, peak int64) rune {
+	if n == 0 || peak == 0 {
+		return heatmapShades[0]
+	}
+	i := 1 + int(n*int64(len(heatmapShades)-2)/peak)
+	if i >= len(heatmapShades) {
+		i = len(heatmapShades) - 1
+	}
+	return heatmapShades[i]
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+// bulkUpdateBreakpoints shows what filter matches, asks for confirmation
+// unless yes is set, then applies action to exactly those breakpoints.
+func bulkUpdateBreakpoints(ctx context.Context, client pb.ControlPlaneClient, action string, filter *pb.BreakpointFilter, yes bool) {
+	preview, err := client.BulkUpdateBreakpoints(ctx, &pb.BulkUpdateBreakpointsRequest{
+		Filter: filter,
+		Action: action,
+		DryRun: true,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if !preview.Success {
+		fmt.Printf("❌ %s\n", preview.RespMessage)
+		os.Exit(1)
+	}
+	if len(preview.Matched) == 0 {
+		fmt.Println("No matching breakpoints")
+		return
+	}
+
+	fmt.Printf("Breakpoints to %s (%d):\n\n", action, len(preview.Matched))
+	ids := make([]string, 0, len(preview.Matched))
+	for _, bp := range preview.Matched {
+		ids = append(ids, bp.Id)
+		label := bp.Id
+		if bp.Name != "" {
+			label = fmt.Sprintf("%s (%s)", bp.Name, bp.Id)
+		}
+		state := ""
+		if !bp.Enabled {
+			state = "  [disabled]"
+		}
+		fmt.Printf("   %s  %s%s%s\n", label, bp.ServiceName, bp.Endpoint, state)
+	}
+	fmt.Println()
+
+	if !yes {
+		fmt.Printf("%s %d breakpoint(s)? [y/N] ", strings.ToUpper(action[:1])+action[1:], len(ids))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Aborted")
+			return
+		}
+	}
+
+	resp, err := client.BulkUpdateBreakpoints(ctx, &pb.BulkUpdateBreakpointsRequest{
+		Filter:        filter,
+		Action:        action,
+		BreakpointIds: ids,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if resp.Success {
+		fmt.Printf("✅ %s\n", resp.RespMessage)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+		os.Exit(1)
+	}
+}
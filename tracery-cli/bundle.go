@@ -0,0 +1,71 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+// writeSupportBundle fetches the control plane's diagnostics dump and packs
+// it into a .tar.gz that can be attached to a bug report.
+func writeSupportBundle(ctx context.Context, client pb.ControlPlaneClient, out string) {
+	resp, err := client.GetSupportBundle(ctx, &pb.GetSupportBundleRequest{})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	generatedAt := time.Unix(resp.GeneratedAt, 0)
+	if out == "" {
+		out = fmt.Sprintf("tracery-support-%s.tar.gz", generatedAt.UTC().Format("20060102-150405"))
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(resp.Files))
+	for name := range resp.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := []byte(resp.Files[name])
+		hdr := &tar.Header{
+			Name:    "tracery-support/" + name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: generatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("✅ Support bundle written to %s\n", out)
+	for _, name := range names {
+		fmt.Printf("   %s\n", name)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// doctorCheck is a single environment check. Remediation is printed when
+// the check fails so the user knows what to do next.
+type doctorCheck struct {
+	Name        string
+	Run         func(ctx context.Context) error
+	Remediation string
+}
+
+var targetWorkloads = []string{"service-a", "service-b", "service-c"}
+
+// controlPlaneHealthURL is the control plane's HTTP NodePort.
+const controlPlaneHealthURL = "http://localhost:30081/health"
+
+// spanSilenceLimit matches the control plane's own otlp_silent self-check.
+const spanSilenceLimit = 5 * time.Minute
+
+func runDoctor(ctx context.Context, namespace string) {
+	// Unlike the other commands, doctor must not exit when the control
+	// plane can't be reached, so the dial here doesn't block; the first
+	// check reports the failure.
+	conn, err := grpc.NewClient(controlPlaneAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("Failed to connect:%v", err)
+	}
+	defer conn.Close()
+	client := pb.NewControlPlaneClient(conn)
+
+	checks := []doctorCheck{
+		{
+			Name: "Control plane reachable",
+			Run: func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+				defer cancel()
+				_, err := client.ListBreakpoints(ctx, &pb.ListBreakpointsRequest{})
+				return err
+			},
+			Remediation: "Check the control-plane pod is running (kubectl get pods -l app=control-plane) and that NodePort 30051 is reachable from this machine.",
+		},
+		{
+			Name: "kubectl available",
+			Run: func(ctx context.Context) error {
+				_, err := exec.LookPath("kubectl")
+				return err
+			},
+			Remediation: "Install kubectl and point it at the tracery cluster (kind export kubeconfig --name tracery).",
+		},
+		{
+			Name: "Control plane receiving spans",
+			Run: func(ctx context.Context) error {
+				last, err := lastSpanAt(ctx)
+				if err != nil {
+					return err
+				}
+				if last.IsZero() {
+					return fmt.Errorf("no spans received since the control plane started")
+				}
+				if age := time.Since(last); age > spanSilenceLimit {
+					return fmt.Errorf("last span received %s ago", age.Round(time.Second))
+				}
+				return nil
+			},
+			Remediation: "Send a request through the services, then check the otel-collector logs for errors exporting to control-plane:4317.",
+		},
+		{
+			Name: "OTLP collector running",
+			Run: func(ctx context.Context) error {
+				out, err := kubectl(ctx, "get", "deployment", "otel-collector", "-n", namespace,
+					"-o", "jsonpath={.status.readyReplicas}")
+				if err != nil {
+					return err
+				}
+				if out == "" || out == "0" {
+					return fmt.Errorf("otel-collector has no ready replicas")
+				}
+				return nil
+			},
+			Remediation: "Deploy the collector with kubectl apply -f k8s/otel-collector.yaml and check its logs for exporter errors.",
+		},
+		{
+			Name: "Sidecar injection enabled on namespace " + namespace,
+			Run: func(ctx context.Context) error {
+				out, err := kubectl(ctx, "get", "namespace", namespace,
+					"-o", "jsonpath={.metadata.labels.istio-injection}")
+				if err != nil {
+					return err
+				}
+				if out != "enabled" {
+					return fmt.Errorf("istio-injection label is %q", out)
+				}
+				return nil
+			},
+			Remediation: "Run kubectl label namespace " + namespace + " istio-injection=enabled --overwrite, then ./redeploy-istio.sh.",
+		},
+		{
+			Name: "Envoy sidecars on target workloads",
+			Run: func(ctx context.Context) error {
+				var missing []string
+				for _, app := range targetWorkloads {
+					out, err := kubectl(ctx, "get", "pods", "-n", namespace, "-l", "app="+app,
+						"-o", "jsonpath={.items[*].spec.containers[*].name}")
+					if err != nil {
+						return err
+					}
+					if !strings.Contains(out, "istio-proxy") {
+						missing = append(missing, app)
+					}
+				}
+				if len(missing) > 0 {
+					return fmt.Errorf("no istio-proxy container in %s", strings.Join(missing, ", "))
+				}
+				return nil
+			},
+			Remediation: "Redeploy the services after enabling injection: ./redeploy-istio.sh (pods should show READY 2/2).",
+		},
+	}
+
+	fmt.Println("Tracery doctor")
+	fmt.Println()
+
+	failed := 0
+	for _, check := range checks {
+		if err := check.Run(ctx); err != nil {
+			failed++
+			fmt.Printf("❌ %s: %v\n", check.Name, err)
+			fmt.Printf("   → %s\n", check.Remediation)
+			continue
+		}
+		fmt.Printf("✅ %s\n", check.Name)
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf("%d of %d checks failed\n", failed, len(checks))
+		os.Exit(1)
+	}
+	fmt.Println("All checks passed")
+}
+
+// lastSpanAt asks the control plane's health endpoint when it last
+// received a span. It returns the zero time if it never has.
+func lastSpanAt(ctx context.Context) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, controlPlaneHealthURL, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	var health struct {
+		LastSpanAt int64 `json:"last_span_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return time.Time{}, fmt.Errorf("reading %s: %v", controlPlaneHealthURL, err)
+	}
+	if health.LastSpanAt == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(health.LastSpanAt, 0), nil
+}
+
+func kubectl(ctx context.Context, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "kubectl", args...).CombinedOutput()
+	trimmed := strings.TrimSpace(string(out))
+	if err != nil {
+		if trimmed != "" {
+			return trimmed, fmt.Errorf("kubectl %s: %s", args[0], trimmed)
+		}
+		return trimmed, fmt.Errorf("kubectl %s: %w", args[0], err)
+	}
+	return trimmed, nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+// eventSchemaVersion is bumped whenever a field in eventRecord is renamed or
+// removed. Adding fields does not change it.
+const eventSchemaVersion = 1
+
+// eventRecord is the machine-readable form of a TraceEvent. Field names are
+// part of the CLI's scripting contract.
+type eventRecord struct {
+	SchemaVersion int               `json:"schema_version"`
+	Type          string            `json:"type"`
+	Time          string            `json:"time"`
+	TraceID       string            `json:"trace_id,omitempty"`
+	Service       string            `json:"service,omitempty"`
+	Endpoint      string            `json:"endpoint,omitempty"`
+	RawEndpoint   string            `json:"raw_endpoint,omitempty"`
+	BreakpointID  string            `json:"breakpoint_id,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	Span          *spanRecord       `json:"span,omitempty"`
+}
+
+// spanRecord is the span metadata carried by span events received over OTLP.
+type spanRecord struct {
+	SpanID        string            `json:"span_id"`
+	ParentSpanID  string            `json:"parent_span_id,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind"`
+	Start         string            `json:"start"`
+	End           string            `json:"end"`
+	DurationMs    float64           `json:"duration_ms"`
+	Status        string            `json:"status"`
+	StatusMessage string            `json:"status_message,omitempty"`
+	Events        []spanEventRecord `json:"events,omitempty"`
+	Links         []spanLinkRecord  `json:"links,omitempty"`
+}
+
+type spanEventRecord struct {
+	Name       string            `json:"name"`
+	Time       string            `json:"time"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type spanLinkRecord struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func newSpanRecord(span *pb.Span) *spanRecord {
+	if span == nil {
+		return nil
+	}
+	rec := &spanRecord{
+		SpanID:        span.SpanId,
+		ParentSpanID:  span.ParentSpanId,
+		Name:          span.Name,
+		Kind:          span.Kind,
+		Start:         formatUnixNano(span.StartTimeUnixNano),
+		End:           formatUnixNano(span.EndTimeUnixNano),
+		DurationMs:    spanDuration(span).Seconds() * 1000,
+		Status:        span.StatusCode,
+		StatusMessage: span.StatusMessage,
+	}
+	for _, e := range span.Events {
+		rec.Events = append(rec.Events, spanEventRecord{
+			Name:       e.Name,
+			Time:       formatUnixNano(e.TimeUnixNano),
+			Attributes: e.Attributes,
+		})
+	}
+	for _, l := range span.Links {
+		rec.Links = append(rec.Links, spanLinkRecord{
+			TraceID:    l.TraceId,
+			SpanID:     l.SpanId,
+			Attributes: l.Attributes,
+		})
+	}
+	return rec
+}
+
+func spanDuration(span *pb.Span) time.Duration {
+	return time.Duration(span.EndTimeUnixNano - span.StartTimeUnixNano)
+}
+
+func formatUnixNano(ns int64) string {
+	return time.Unix(0, ns).UTC().Format(time.RFC3339Nano)
+}
+
+func newEventRecord(event *pb.TraceEvent) eventRecord {
+	eventType := event.EventType
+	if eventType == "" {
+		eventType = eventTypeSpan
+	}
+	return eventRecord{
+		SchemaVersion: eventSchemaVersion,
+		Type:          eventType,
+		Time:          time.Unix(event.Timestamp, 0).UTC().Format(time.RFC3339),
+		TraceID:       event.TraceId,
+		Service:       event.ServiceName,
+		Endpoint:      event.Endpoint,
+		RawEndpoint:   event.RawEndpoint,
+		BreakpointID:  event.BreakpointId,
+		Attributes:    event.Attributes,
+		Span:          newSpanRecord(event.Span),
+	}
+}
+
+// streamEvents prints the current breakpoints as events and, with follow,
+// keeps printing every event the control plane publishes.
+func streamEvents(ctx context.Context, client pb.ControlPlaneClient, follow bool, output string, sub *pb.StreamTracesRequest) {
+	if output != "json" && output != "text" {
+		log.Fatalf("Unknown output format %q (want json or text)", output)
+	}
+
+	var stream pb.ControlPlane_StreamTracesClient
+	if follow {
+		// Subscribe before listing so nothing published in between is lost.
+		var err error
+		stream, err = client.StreamTraces(ctx, sub)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	resp, err := client.ListBreakpoints(ctx, &pb.ListBreakpointsRequest{})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	emit := func(event *pb.TraceEvent) {
+		if output == "text" {
+			fmt.Println(formatEvent(event, func(_, s string) string { return s }))
+			return
+		}
+		if err := enc.Encode(newEventRecord(event)); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	for _, bp := range resp.Breakpoints {
+		emit(&pb.TraceEvent{
+			ServiceName:  bp.ServiceName,
+			Endpoint:     bp.Endpoint,
+			Timestamp:    bp.CreatedAt,
+			Attributes:   bp.Conditions,
+			EventType:    eventTypeBreakpointRegistered,
+			BreakpointId: bp.Id,
+		})
+	}
+
+	if !follow {
+		return
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			log.Fatalf("Stream Error: %v", err)
+		}
+		emit(event)
+	}
+}
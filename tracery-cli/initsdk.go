@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// scaffoldFramework describes the wiring generated for one server framework.
+type scaffoldFramework struct {
+	Imports   []string
+	Wiring    string
+	Deps      []string
+	NextSteps []string
+}
+
+var scaffoldFrameworks = map[string]scaffoldFramework{
+	"net/http": {
+		Imports: []string{
+			`"net/http"`,
+			`"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"`,
+		},
+		Wiring: `
+// traceHandler wraps a handler so every request starts (or continues) a trace.
+// Register it like: http.Handle("/order", traceHandler("/order", http.HandlerFunc(handleOrder)))
+func traceHandler(route string, h http.Handler) http.Handler {
+	return otelhttp.NewHandler(h, route)
+}
+
+// tracedClient propagates the trace context on outgoing calls so downstream
+// services join the same trace.
+func tracedClient() *http.Client {
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+}
+`,
+		Deps: []string{"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"},
+		NextSteps: []string{
+			"Wrap each route with traceHandler when registering it",
+			"Use tracedClient() for calls to other services",
+		},
+	},
+	"gin": {
+		Imports: []string{
+			`"github.com/gin-gonic/gin"`,
+			`"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"`,
+		},
+		Wiring: `
+// useTracing installs the tracing middleware on the router. Call it before
+// registering routes.
+func useTracing(r *gin.Engine) {
+	r.Use(otelgin.Middleware(traceryServiceName))
+}
+`,
+		Deps: []string{"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"},
+		NextSteps: []string{
+			"Call useTracing(router) right after gin.New()/gin.Default()",
+		},
+	},
+	"grpc": {
+		Imports: []string{
+			`"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"`,
+		},
+		Wiring: `
+// tracedServerOptions returns the options that make a gRPC server join
+// incoming traces. Use it like: grpc.NewServer(tracedServerOptions()...)
+func tracedServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler())}
+}
+
+// tracedDialOptions propagates the trace context on outgoing calls.
+func tracedDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{grpc.WithStatsHandler(otelgrpc.NewClientHandler())}
+}
+`,
+		Deps: []string{"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"},
+		NextSteps: []string{
+			"Pass tracedServerOptions()... to grpc.NewServer",
+			"Pass tracedDialOptions()... when creating clients for other services",
+		},
+	},
+}
+
+var scaffoldTemplate = template.Must(template.New("setup").Parse(`// Generated by tracery init-sdk.
+
+package {{.Package}}
+
+import (
+	"context"
+	"log"
+	"os"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+{{range .Framework.Imports}}	{{.}}
+{{end}})
+
+const traceryServiceName = "{{.Service}}"
+
+// initTracer exports spans to the collector tracery reads from. Call it first
+// thing in main and defer the returned cleanup.
+func initTracer() func() {
+	ctx := context.Background()
+
+	otelEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if otelEndpoint == "" {
+		otelEndpoint = "otel-collector:4317"
+	}
+
+	conn, err := grpc.NewClient(otelEndpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create gRPC connection: %v", err)
+	}
+
+	traceExporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
+	if err != nil {
+		log.Fatalf("Failed to create trace exporter: %v", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(traceryServiceName)),
+		resource.WithAttributes(buildAttributes()...),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create resource: %v", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return func() {
+		if err := tp.Shutdown(ctx); err != nil {
+			log.Printf("Error shutting down tracer provider: %v", err)
+		}
+	}
+}
+
+// buildAttributes report the module and commit the binary was built from, so
+// tracery can check breakpoint source locations against the deployed code.
+func buildAttributes() []attribute.KeyValue {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	attrs := []attribute.KeyValue{attribute.String("tracery.build.module", info.Main.Path)}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		attrs = append(attrs, semconv.ServiceVersion(info.Main.Version))
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			attrs = append(attrs, attribute.String("vcs.ref.head.revision", setting.Value))
+		}
+	}
+	return attrs
+}
+{{.Framework.Wiring}}`))
+
+func initSDK(framework, service, pkg, dir string, force bool) error {
+	fw, ok := scaffoldFrameworks[framework]
+	if !ok {
+		return fmt.Errorf("unsupported framework %q (want net/http, gin or grpc)", framework)
+	}
+
+	var buf bytes.Buffer
+	err := scaffoldTemplate.Execute(&buf, struct {
+		Package   string
+		Service   string
+		Framework scaffoldFramework
+	}{pkg, service, fw})
+	if err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	path := filepath.Join(dir, "tracery_setup.go")
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	}
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Generated %s for %s\n", path, framework)
+	fmt.Println("\nNext steps:")
+	fmt.Println("  1. Add the dependencies:")
+	fmt.Println("     go get go.opentelemetry.io/otel go.opentelemetry.io/otel/sdk go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc")
+	for _, dep := range fw.Deps {
+		fmt.Printf("     go get %s\n", dep)
+	}
+	fmt.Println("  2. In main: cleanup := initTracer(); defer cleanup()")
+	for i, step := range fw.NextSteps {
+		fmt.Printf("  %d. %s\n", i+3, step)
+	}
+	return nil
+}
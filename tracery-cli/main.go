@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -11,50 +13,314 @@ import (
 	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
+// controlPlaneAddr is the control plane's gRPC NodePort.
+const controlPlaneAddr = "localhost:30051"
+
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
 	}
 
-	conn, err := grpc.NewClient("localhost:30051",
+	// doctor connects on its own, without blocking, so it can still run
+	// and explain what is wrong when the control plane is down.
+	if os.Args[1] == "doctor" {
+		fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+		namespace := fs.String("namespace", "default", "namespace of the target workloads")
+		fs.Parse(os.Args[2:])
+		runDoctor(context.Background(), *namespace)
+		return
+	}
+
+	conn, err := grpc.NewClient(controlPlaneAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
 		grpc.WithTimeout(5*time.Second),
 	)
 	if err != nil {
-		log.Fatal("Failed to connect:%v", err)
+		log.Fatalf("Failed to connect:%v", err)
 	}
 
 	defer conn.Close()
 	client := pb.NewControlPlaneClient(conn)
 	ctx := context.Background()
+	if user := os.Getenv("USER"); user != "" {
+		// Lets control-plane logs say who ran a command.
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-tracery-user", user)
+	}
 
 	switch os.Args[1] {
 	case "set-breakpoint":
-		if len(os.Args) < 4 {
-			fmt.Println("Usage: dcdot-cli set-breakpoint <service> <endpoint> [key=value...]")
+		fs := flag.NewFlagSet("set-breakpoint", flag.ExitOnError)
+		name := fs.String("name", "", "unique breakpoint name; an existing name is updated in place")
+		expr := fs.String("expr", "", `condition expression, e.g. 'amount > 1000 && customer_id.startsWith("vip-")'`)
+		maxHits := fs.Int64("max-hits", 0, "disable the breakpoint after this many hits (0 = unlimited)")
+		deleteAfter := fs.Bool("delete-after", false, "delete instead of disabling once --max-hits is reached")
+		ttl := fs.Duration("ttl", 0, "retire the breakpoint after this long, e.g. 30m (0 = never)")
+		keepExpired := fs.Bool("keep-expired", false, "disable instead of deleting once --ttl passes")
+		singleSpan := fs.Bool("single-span", false, "match each span on its own instead of the service's spans in the trace combined")
+		source := fs.String("source", "", "source location the breakpoint stands for, as file[:line] within the repo")
+		repo := fs.String("repo", "", "repository --source is in, e.g. github.com/org/repo")
+		fs.Parse(os.Args[2:])
+		if fs.NArg() < 2 {
+			fmt.Println("Usage: dcdot-cli set-breakpoint [--name <name>] [--expr <expression>] [--max-hits <n> [--delete-after]] [--ttl <d> [--keep-expired]] [--single-span] [--source <file[:line]> [--repo <repo>]] <service> <endpoint> [key=value...]")
+			os.Exit(1)
+		}
+		loc, err := parseSourceLocation(*source, *repo)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
 			os.Exit(1)
 		}
-		setBreakpoint(ctx, client, os.Args[2:])
+		setBreakpoint(ctx, client, &pb.RegisterBreakPointRequest{
+			Name:               *name,
+			Expression:         *expr,
+			MaxHits:            *maxHits,
+			DeleteAfterMaxHits: *deleteAfter,
+			TtlSeconds:         int64(ttl.Seconds()),
+			KeepAfterExpiry:    *keepExpired,
+			SingleSpan:         *singleSpan,
+			Source:             loc,
+		}, fs.Args())
 	case "list-breakpoints":
 		listBreakpoints(ctx, client)
-	case "delete-breakpoint":
-		if len(os.Args) < 3 {
-			fmt.Println("Usage: dcdot-cli delete-breakpoint <id>")
+	case "delete-breakpoint", "enable-breakpoint", "disable-breakpoint":
+		action := strings.TrimSuffix(os.Args[1], "-breakpoint")
+		fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+		service := fs.String("service", "", "every breakpoint on this service")
+		endpoint := fs.String("endpoint", "", "every breakpoint on this endpoint")
+		olderThan := fs.Duration("older-than", 0, "every breakpoint created longer ago than this, e.g. 24h")
+		yes := fs.Bool("yes", false, "skip the confirmation prompt")
+		var enabled, disabled *bool
+		if action == "delete" {
+			enabled = fs.Bool("enabled", false, "only enabled breakpoints")
+			disabled = fs.Bool("disabled", false, "only disabled breakpoints")
+		}
+		fs.Parse(os.Args[2:])
+
+		filter := &pb.BreakpointFilter{
+			ServiceName:      *service,
+			Endpoint:         *endpoint,
+			OlderThanSeconds: int64(olderThan.Seconds()),
+		}
+		if enabled != nil && *enabled {
+			filter.State = "enabled"
+		}
+		if disabled != nil && *disabled {
+			filter.State = "disabled"
+		}
+		bulk := filter.ServiceName != "" || filter.Endpoint != "" || filter.State != "" || filter.OlderThanSeconds > 0
+		if bulk == (fs.NArg() > 0) {
+			if action == "delete" {
+				fmt.Println("Usage: dcdot-cli delete-breakpoint <id|name>")
+				fmt.Println("       dcdot-cli delete-breakpoint [--service <name>] [--endpoint <path>] [--enabled|--disabled] [--older-than <d>] [--yes]")
+			} else {
+				fmt.Printf("Usage: dcdot-cli %s <id|name>\n", os.Args[1])
+				fmt.Printf("       dcdot-cli %s [--service <name>] [--endpoint <path>] [--older-than <d>] [--yes]\n", os.Args[1])
+			}
 			os.Exit(1)
 		}
-		deleteBreakpoint(ctx, client, os.Args[2])
+
+		switch {
+		case bulk:
+			bulkUpdateBreakpoints(ctx, client, action, filter, *yes)
+		case action == "delete":
+			deleteBreakpoint(ctx, client, fs.Arg(0))
+		default:
+			setBreakpointEnabled(ctx, client, fs.Arg(0), action == "enable")
+		}
 	case "watch-traces":
-		watchTraces(ctx, client)
+		fs := flag.NewFlagSet("watch-traces", flag.ExitOnError)
+		opts := watchOptions{}
+		fs.BoolVar(&opts.HitsOnly, "hits-only", false, "only show breakpoint hits")
+		fs.BoolVar(&opts.Aggregate, "aggregate", false, "show per-service rates and per-breakpoint hit counts instead of events")
+		fs.BoolVar(&opts.NoColor, "no-color", os.Getenv("NO_COLOR") != "", "disable colored output")
+		fs.DurationVar(&opts.Interval, "interval", 2*time.Second, "refresh interval for rate counters")
+		fs.StringVar(&opts.Class, "class", "interactive", "subscriber class (see subscriber-classes)")
+		fs.Parse(os.Args[2:])
+		watchTraces(ctx, client, opts)
+	case "events":
+		fs := flag.NewFlagSet("events", flag.ExitOnError)
+		follow := fs.Bool("follow", false, "keep streaming new events")
+		output := fs.String("output", "json", "output format: json (newline-delimited) or text")
+		class := fs.String("class", "archival", "subscriber class used with --follow (see subscriber-classes)")
+		types := fs.String("types", "", "comma-separated event types to receive with --follow (default all)")
+		fs.Parse(os.Args[2:])
+		streamEvents(ctx, client, *follow, *output, &pb.StreamTracesRequest{
+			SubscriberClass: *class,
+			EventTypes:      splitList(*types),
+		})
 	case "get-snapshot":
 		if len(os.Args) < 3 {
-			fmt.Println("Usage: dcdot-cli get-snapshot <trace-id>")
+			fmt.Println("Usage: dcdot-cli get-snapshot <trace-id|alias> [service]")
 			os.Exit(1)
 		}
-		getSnapshot(ctx, client, os.Args[2])
+		service := ""
+		if len(os.Args) > 3 {
+			service = os.Args[3]
+		}
+		getSnapshot(ctx, client, os.Args[2], service)
+	case "watch-snapshots":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: dcdot-cli watch-snapshots <trace-id|alias> [service]")
+			os.Exit(1)
+		}
+		service := ""
+		if len(os.Args) > 3 {
+			service = os.Args[3]
+		}
+		watchSnapshots(ctx, client, os.Args[2], service)
+	case "list-snapshots":
+		fs := flag.NewFlagSet("list-snapshots", flag.ExitOnError)
+		trace := fs.String("trace", "", "only snapshots of this trace ID or alias")
+		service := fs.String("service", "", "only snapshots from this service")
+		checkpoint := fs.String("checkpoint", "", "only snapshots taken at this checkpoint")
+		since := fs.Duration("since", 0, "only snapshots captured within this long, e.g. 1h")
+		until := fs.Duration("until", 0, "only snapshots captured longer ago than this")
+		limit := fs.Int("limit", 0, "snapshots per page (default 50)")
+		page := fs.String("page", "", "page token printed by the previous page")
+		data := fs.Bool("data", false, "print each snapshot's data")
+		fs.Parse(os.Args[2:])
+		req := &pb.ListSnapshotsRequest{
+			TraceId:     *trace,
+			ServiceName: *service,
+			Checkpoint:  *checkpoint,
+			PageSize:    int32(*limit),
+			PageToken:   *page,
+			IncludeData: *data,
+		}
+		if *since > 0 {
+			req.Since = time.Now().Add(-*since).Unix()
+		}
+		if *until > 0 {
+			req.Until = time.Now().Add(-*until).Unix()
+		}
+		listSnapshots(ctx, client, req)
+	case "diff-snapshots":
+		fs := flag.NewFlagSet("diff-snapshots", flag.ExitOnError)
+		all := fs.Bool("all", false, "also list variables that are the same in every snapshot")
+		fs.Parse(os.Args[2:])
+		if fs.NArg() < 1 {
+			fmt.Println("Usage: dcdot-cli diff-snapshots [--all] <trace-id|alias> [variable...]")
+			os.Exit(1)
+		}
+		diffSnapshots(ctx, client, fs.Arg(0), fs.Args()[1:], *all)
+	case "purge-snapshots":
+		fs := flag.NewFlagSet("purge-snapshots", flag.ExitOnError)
+		olderThan := fs.Duration("older-than", 0, "delete snapshots captured longer ago than this, e.g. 72h")
+		fs.Parse(os.Args[2:])
+		if *olderThan <= 0 {
+			fmt.Println("Usage: dcdot-cli purge-snapshots --older-than <duration>")
+			os.Exit(1)
+		}
+		purgeSnapshots(ctx, client, *olderThan)
+	case "delete-snapshot":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: dcdot-cli delete-snapshot <trace-id|alias> <snapshot-id>")
+			os.Exit(1)
+		}
+		deleteSnapshot(ctx, client, os.Args[2], os.Args[3])
+	case "alias":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: dcdot-cli alias <key> <trace-id>")
+			os.Exit(1)
+		}
+		registerAlias(ctx, client, os.Args[2], os.Args[3])
+	case "tail":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: dcdot-cli tail <trace-id|alias>")
+			os.Exit(1)
+		}
+		tailTrace(ctx, client, os.Args[2])
+	case "get-trace":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: dcdot-cli get-trace <trace-id|alias>")
+			os.Exit(1)
+		}
+		getTrace(ctx, client, os.Args[2])
+	case "set-sampling":
+		if len(os.Args) < 5 {
+			fmt.Println("Usage: dcdot-cli set-sampling <service> <endpoint|*> <rate> [key=value...]")
+			os.Exit(1)
+		}
+		setSampling(ctx, client, os.Args[2:])
+	case "get-sampling":
+		service := ""
+		if len(os.Args) > 2 {
+			service = os.Args[2]
+		}
+		getSampling(ctx, client, service)
+	case "delete-sampling":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: dcdot-cli delete-sampling <rule-id>")
+			os.Exit(1)
+		}
+		deleteSampling(ctx, client, os.Args[2])
+	case "init-sdk":
+		fs := flag.NewFlagSet("init-sdk", flag.ExitOnError)
+		framework := fs.String("framework", "net/http", "server framework: net/http, gin or grpc")
+		service := fs.String("service", "", "service name reported in traces (required)")
+		pkg := fs.String("package", "main", "package name of the generated file")
+		dir := fs.String("dir", ".", "directory to write tracery_setup.go into")
+		force := fs.Bool("force", false, "overwrite an existing tracery_setup.go")
+		fs.Parse(os.Args[2:])
+		if *service == "" {
+			fmt.Println("Usage: dcdot-cli init-sdk --service <name> [--framework net/http|gin|grpc] [--package <pkg>] [--dir <dir>]")
+			os.Exit(1)
+		}
+		if err := initSDK(*framework, *service, *pkg, *dir, *force); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	case "endpoint-rewrites":
+		endpoint := ""
+		if len(os.Args) > 2 {
+			endpoint = os.Args[2]
+		}
+		listEndpointRewrites(ctx, client, endpoint)
+	case "set-endpoint-rewrite":
+		if len(os.Args) < 4 {
+			fmt.Println("Usage: dcdot-cli set-endpoint-rewrite <pattern> <replacement>")
+			os.Exit(1)
+		}
+		setEndpointRewrite(ctx, client, os.Args[2], os.Args[3])
+	case "delete-endpoint-rewrite":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: dcdot-cli delete-endpoint-rewrite <pattern>")
+			os.Exit(1)
+		}
+		deleteEndpointRewrite(ctx, client, os.Args[2])
+	case "subscriber-classes":
+		listSubscriberClasses(ctx, client)
+	case "set-subscriber-class":
+		fs := flag.NewFlagSet("set-subscriber-class", flag.ExitOnError)
+		buffer := fs.Int("buffer", 0, "buffer size for new subscribers (0 keeps the current size)")
+		drop := fs.String("drop", "", "drop policy: drop_newest, drop_oldest or disconnect")
+		fs.Parse(os.Args[2:])
+		if fs.NArg() < 1 {
+			fmt.Println("Usage: dcdot-cli set-subscriber-class [--buffer <n>] [--drop <policy>] <name>")
+			os.Exit(1)
+		}
+		setSubscriberClass(ctx, client, fs.Arg(0), *buffer, *drop)
+	case "analytics":
+		fs := flag.NewFlagSet("analytics", flag.ExitOnError)
+		breakpoint := fs.String("breakpoint", "", "only this breakpoint (id or name)")
+		hours := fs.Int64("hours", 24, "how many hours back to show, up to 168")
+		top := fs.Int("top", 10, "attribute values to list")
+		fs.Parse(os.Args[2:])
+		if *hours <= 0 || *hours > 168 {
+			fmt.Println("Usage: dcdot-cli analytics [--breakpoint <id|name>] [--hours <1-168>] [--top <n>]")
+			os.Exit(1)
+		}
+		showAnalytics(ctx, client, *breakpoint, *hours, int32(*top))
+	case "storage-stats":
+		getStorageStats(ctx, client)
+	case "support-bundle":
+		fs := flag.NewFlagSet("support-bundle", flag.ExitOnError)
+		out := fs.String("out", "", "archive path (default tracery-support-<time>.tar.gz)")
+		fs.Parse(os.Args[2:])
+		writeSupportBundle(ctx, client, *out)
 	default:
 		fmt.Printf("Unknown command :%s\n", os.Args[1])
 		printUsage()
@@ -66,14 +332,43 @@ func main() {
 func printUsage() {
 	fmt.Println("DCDOT CLI")
 	fmt.Println("\nCommands:")
-	fmt.Println("  set-breakpoint <service> <endpoint> [conditions...]")
+	fmt.Println("  set-breakpoint [--name <name>] [--expr <expression>] [--max-hits <n> [--delete-after]]")
+	fmt.Println("                 [--ttl <d> [--keep-expired]] [--single-span] [--source <file[:line]> [--repo <repo>]]")
+	fmt.Println("                 <service> <endpoint> [conditions...]")
 	fmt.Println("  list-breakpoints")
-	fmt.Println("  delete-breakpoint <id>")
-	fmt.Println("  watch-traces")
-	fmt.Println("  get-snapshot <trace-id>")
+	fmt.Println("  delete-breakpoint <id|name>")
+	fmt.Println("  delete-breakpoint [--service <name>] [--endpoint <path>] [--enabled|--disabled] [--older-than <d>] [--yes]")
+	fmt.Println("  enable-breakpoint <id|name>")
+	fmt.Println("  disable-breakpoint <id|name>")
+	fmt.Println("  enable-breakpoint|disable-breakpoint [--service <name>] [--endpoint <path>] [--older-than <d>] [--yes]")
+	fmt.Println("  watch-traces [--hits-only] [--aggregate] [--no-color] [--interval <d>] [--class <class>]")
+	fmt.Println("  events [--follow] [--output json|text] [--class <class>] [--types <t1,t2>]")
+	fmt.Println("  get-snapshot <trace-id|alias> [service]")
+	fmt.Println("  watch-snapshots <trace-id|alias> [service]")
+	fmt.Println("  list-snapshots [--trace <id|alias>] [--service <name>] [--checkpoint <name>]")
+	fmt.Println("                 [--since <d>] [--until <d>] [--limit <n>] [--page <token>] [--data]")
+	fmt.Println("  diff-snapshots [--all] <trace-id|alias> [variable...]")
+	fmt.Println("  purge-snapshots --older-than <duration>")
+	fmt.Println("  delete-snapshot <trace-id|alias> <snapshot-id>")
+	fmt.Println("  alias <key> <trace-id>")
+	fmt.Println("  tail <trace-id|alias>")
+	fmt.Println("  get-trace <trace-id|alias>")
+	fmt.Println("  set-sampling <service> <endpoint|*> <rate> [force conditions...]")
+	fmt.Println("  get-sampling [service]")
+	fmt.Println("  delete-sampling <rule-id>")
+	fmt.Println("  init-sdk --service <name> [--framework net/http|gin|grpc]")
+	fmt.Println("  doctor [--namespace <ns>]")
+	fmt.Println("  endpoint-rewrites [endpoint]")
+	fmt.Println("  set-endpoint-rewrite <pattern> <replacement>")
+	fmt.Println("  delete-endpoint-rewrite <pattern>")
+	fmt.Println("  subscriber-classes")
+	fmt.Println("  set-subscriber-class [--buffer <n>] [--drop <policy>] <name>")
+	fmt.Println("  analytics [--breakpoint <id|name>] [--hours <n>] [--top <n>]")
+	fmt.Println("  storage-stats")
+	fmt.Println("  support-bundle [--out <file>]")
 }
 
-func setBreakpoint(ctx context.Context, client pb.ControlPlaneClient, args []string) {
+func setBreakpoint(ctx context.Context, client pb.ControlPlaneClient, req *pb.RegisterBreakPointRequest, args []string) {
 	conditions := make(map[string]string)
 	for i := 2; i < len(args); i++ {
 		parts := strings.SplitN(args[i], "=", 2)
@@ -82,27 +377,56 @@ func setBreakpoint(ctx context.Context, client pb.ControlPlaneClient, args []str
 		}
 	}
 
-	resp, err := client.RegisterBreakpoint(ctx, &pb.RegisterBreakPointRequest{
-		ServiceName: args[0],
-		Endpoint:    args[1],
-		Conditions:  conditions,
-	})
+	req.ServiceName = args[0]
+	req.Endpoint = args[1]
+	req.Conditions = conditions
+	resp, err := client.RegisterBreakpoint(ctx, req)
 
 	if err != nil {
-		log.Fatal("Error:%v", err)
+		log.Fatalf("Error:%v", err)
+	}
+	if !resp.Success {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+		return
 	}
 
 	fmt.Printf("✅ Breakpoint: %s\n", resp.BreakpointId)
+	if req.Name != "" {
+		fmt.Printf("   Name: %s\n", req.Name)
+	}
 	fmt.Printf("   Service: %s%s\n", args[0], args[1])
 	if len(conditions) > 0 {
 		fmt.Printf("   Conditions: %v\n", conditions)
 	}
+	if req.Expression != "" {
+		fmt.Printf("   Expression: %s\n", req.Expression)
+	}
+	if req.MaxHits > 0 {
+		action := "disabled"
+		if req.DeleteAfterMaxHits {
+			action = "deleted"
+		}
+		fmt.Printf("   Max hits: %d (then %s)\n", req.MaxHits, action)
+	}
+	if req.TtlSeconds > 0 {
+		action := "deleted"
+		if req.KeepAfterExpiry {
+			action = "disabled"
+		}
+		fmt.Printf("   Expires in: %s (then %s)\n", time.Duration(req.TtlSeconds)*time.Second, action)
+	}
+	if req.SingleSpan {
+		fmt.Println("   Matches single spans only")
+	}
+	if resp.Source != nil {
+		fmt.Printf("   Source: %s%s\n", sourceString(resp.Source), sourceStatus(resp.Source))
+	}
 }
 
 func listBreakpoints(ctx context.Context, client pb.ControlPlaneClient) {
 	resp, err := client.ListBreakpoints(ctx, &pb.ListBreakpointsRequest{})
 	if err != nil {
-		log.Fatal("Error:%v", err)
+		log.Fatalf("Error:%v", err)
 	}
 
 	if len(resp.Breakpoints) == 0 {
@@ -110,13 +434,38 @@ func listBreakpoints(ctx context.Context, client pb.ControlPlaneClient) {
 		return
 	}
 
-	fmt.Println("BreakPoints (%d):\n\n", len(resp.Breakpoints))
+	fmt.Printf("BreakPoints (%d):\n\n", len(resp.Breakpoints))
 	for i, bp := range resp.Breakpoints {
-		fmt.Printf("%d. %s\n", i+1, bp.Id)
+		if bp.Name != "" {
+			fmt.Printf("%d. %s (%s)\n", i+1, bp.Name, bp.Id)
+		} else {
+			fmt.Printf("%d. %s\n", i+1, bp.Id)
+		}
 		fmt.Printf("   %s%s\n", bp.ServiceName, bp.Endpoint)
+		if !bp.Enabled {
+			fmt.Println("   Disabled")
+		}
+		if bp.MaxHits > 0 {
+			fmt.Printf("   Hits: %d/%d\n", bp.HitCount, bp.MaxHits)
+		} else if bp.HitCount > 0 {
+			fmt.Printf("   Hits: %d\n", bp.HitCount)
+		}
+		if bp.ExpiresAt > 0 {
+			expires := time.Unix(bp.ExpiresAt, 0)
+			fmt.Printf("   Expires: %s (in %s)\n", expires.Format(time.RFC3339), time.Until(expires).Round(time.Second))
+		}
 		if len(bp.Conditions) > 0 {
 			fmt.Printf("   Conditions: %v\n", bp.Conditions)
 		}
+		if bp.Expression != "" {
+			fmt.Printf("   Expression: %s\n", bp.Expression)
+		}
+		if bp.SingleSpan {
+			fmt.Println("   Single span")
+		}
+		if bp.Source != nil {
+			fmt.Printf("   Source: %s%s\n", sourceString(bp.Source), sourceStatus(bp.Source))
+		}
 		fmt.Println()
 	}
 }
@@ -126,7 +475,7 @@ func deleteBreakpoint(ctx context.Context, client pb.ControlPlaneClient, id stri
 		BreakpointId: id,
 	})
 	if err != nil {
-		log.Fatal("Error:%v", err)
+		log.Fatalf("Error:%v", err)
 	}
 
 	if resp.Success {
@@ -137,32 +486,115 @@ func deleteBreakpoint(ctx context.Context, client pb.ControlPlaneClient, id stri
 
 }
 
-func watchTraces(ctx context.Context, client pb.ControlPlaneClient) {
-	fmt.Println("Watching traces (Ctrl+C to stop)...\n")
-	stream, err := client.StreamTraces(ctx, &pb.StreamTracesRequest{})
+func purgeSnapshots(ctx context.Context, client pb.ControlPlaneClient, olderThan time.Duration) {
+	resp, err := client.PurgeSnapshots(ctx, &pb.PurgeSnapshotsRequest{
+		OlderThanSeconds: int64(olderThan.Seconds()),
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✅ %s\n", resp.RespMessage)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+	}
+}
+
+func deleteSnapshot(ctx context.Context, client pb.ControlPlaneClient, traceID, snapshotID string) {
+	resp, err := client.DeleteSnapshot(ctx, &pb.DeleteSnapshotRequest{
+		TraceId:    traceID,
+		SnapshotId: snapshotID,
+	})
 	if err != nil {
-		log.Fatal("Error: %v", err)
+		log.Fatalf("Error: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✅ %s\n", resp.RespMessage)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+	}
+}
+
+func setBreakpointEnabled(ctx context.Context, client pb.ControlPlaneClient, id string, enabled bool) {
+	resp, err := client.SetBreakpointEnabled(ctx, &pb.SetBreakpointEnabledRequest{
+		BreakpointId: id,
+		Enabled:      enabled,
+	})
+	if err != nil {
+		log.Fatalf("Error:%v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✅ %s: %s\n", resp.RespMessage, id)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+	}
+}
+
+func registerAlias(ctx context.Context, client pb.ControlPlaneClient, alias, traceID string) {
+	resp, err := client.RegisterTraceAlias(ctx, &pb.RegisterTraceAliasRequest{
+		Alias:   alias,
+		TraceId: traceID,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✅ %s\n", resp.RespMessage)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+	}
+}
+
+func tailTrace(ctx context.Context, client pb.ControlPlaneClient, traceID string) {
+	fmt.Printf("Tailing trace %s (Ctrl+C to stop)...\n\n", traceID)
+	stream, err := client.StreamTrace(ctx, &pb.StreamTraceRequest{TraceId: traceID})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
 	}
 
 	for {
 		event, err := stream.Recv()
+		if err == io.EOF {
+			fmt.Println("\nTrace finished")
+			return
+		}
 		if err != nil {
-			log.Fatal("Stream Error: %v", err)
+			log.Fatalf("Stream Error: %v", err)
 		}
-		fmt.Printf("[%s] %s %s%s\n", 
+		if event.EventType == eventTypeEventsDropped {
+			fmt.Printf("... %s event(s) dropped, the trace shown is incomplete\n", event.Attributes["dropped"])
+			continue
+		}
+		fmt.Printf("[%s] %s%s\n",
 			time.Unix(event.Timestamp, 0).Format("15:04:05"),
-			event.TraceId, event.ServiceName, event.Endpoint)
+			event.ServiceName, event.Endpoint)
+		for k, v := range event.Attributes {
+			fmt.Printf("   %s=%s\n", k, v)
+		}
 	}
 }
 
-func getSnapshot(ctx context.Context, client pb.ControlPlaneClient, traceID string) {
-	resp, err := client.GetSnapshot(ctx, &pb.GetSnapshotRequest{TraceId: traceID})
+func getSnapshot(ctx context.Context, client pb.ControlPlaneClient, traceID, service string) {
+	resp, err := client.GetSnapshot(ctx, &pb.GetSnapshotRequest{TraceId: traceID, ServiceName: service})
 	if err != nil {
 		log.Fatalf("Error: %v", err)
 	}
-	if resp.Success {
-		fmt.Println(resp.SnapshotData)
-	} else {
+	if !resp.Success {
 		fmt.Printf("❌ %s\n", resp.RespMessage)
+		return
+	}
+
+	fmt.Printf("Snapshots for trace %s (%d):\n", resp.TraceId, len(resp.Snapshots))
+	for i, snap := range resp.Snapshots {
+		fmt.Printf("\n%d. %s%s at %s\n", i+1, snap.ServiceName, snap.Endpoint,
+			time.Unix(snap.CapturedAt, 0).Format(time.RFC3339))
+		if snap.BreakpointId != "" {
+			fmt.Printf("   Breakpoint: %s\n", snap.BreakpointId)
+		}
+		printSnapshotData(snap)
 	}
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+// listEndpointRewrites prints the rewrites in the order they run and, given
+// an endpoint, what it normalizes to.
+func listEndpointRewrites(ctx context.Context, client pb.ControlPlaneClient, endpoint string) {
+	resp, err := client.GetEndpointRewrites(ctx, &pb.GetEndpointRewritesRequest{Endpoint: endpoint})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Endpoint rewrites (%d, applied in order):\n\n", len(resp.Rewrites))
+	for i, r := range resp.Rewrites {
+		fmt.Printf("%d. %s -> %s\n", i+1, r.Pattern, r.Replacement)
+	}
+	if endpoint != "" {
+		fmt.Printf("\n%s normalizes to %s\n", endpoint, resp.Normalized)
+	}
+}
+
+func setEndpointRewrite(ctx context.Context, client pb.ControlPlaneClient, pattern, replacement string) {
+	resp, err := client.SetEndpointRewrite(ctx, &pb.SetEndpointRewriteRequest{
+		Pattern:     pattern,
+		Replacement: replacement,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✅ %s\n", resp.RespMessage)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+	}
+}
+
+func deleteEndpointRewrite(ctx context.Context, client pb.ControlPlaneClient, pattern string) {
+	resp, err := client.DeleteEndpointRewrite(ctx, &pb.DeleteEndpointRewriteRequest{Pattern: pattern})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✅ %s\n", resp.RespMessage)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+	}
+}
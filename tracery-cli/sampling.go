@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+func setSampling(ctx context.Context, client pb.ControlPlaneClient, args []string) {
+	endpoint := args[1]
+	if endpoint == "*" {
+		endpoint = ""
+	}
+
+	rate, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		log.Fatalf("Invalid rate %q: %v", args[2], err)
+	}
+
+	forceConditions := make(map[string]string)
+	for _, arg := range args[3:] {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) == 2 {
+			forceConditions[parts[0]] = parts[1]
+		}
+	}
+
+	resp, err := client.SetSamplingRule(ctx, &pb.SetSamplingRuleRequest{
+		ServiceName:     args[0],
+		Endpoint:        endpoint,
+		Rate:            rate,
+		ForceConditions: forceConditions,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if !resp.Success {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+		return
+	}
+	fmt.Printf("✅ Sampling rule: %s\n", resp.RuleId)
+	fmt.Printf("   %s\n", resp.RespMessage)
+	if len(forceConditions) > 0 {
+		fmt.Printf("   Always sample: %v\n", forceConditions)
+	}
+}
+
+func getSampling(ctx context.Context, client pb.ControlPlaneClient, service string) {
+	resp, err := client.GetSamplingPolicy(ctx, &pb.GetSamplingPolicyRequest{ServiceName: service})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Default rate: %.0f%% (policy version %d)\n\n", resp.DefaultRate*100, resp.Version)
+	if len(resp.Rules) == 0 {
+		fmt.Println("No sampling rules")
+		return
+	}
+
+	for i, rule := range resp.Rules {
+		endpoint := rule.Endpoint
+		if endpoint == "" {
+			endpoint = "/*"
+		}
+		fmt.Printf("%d. %s\n", i+1, rule.Id)
+		fmt.Printf("   %s%s at %.0f%%\n", rule.ServiceName, endpoint, rule.Rate*100)
+		if len(rule.ForceConditions) > 0 {
+			fmt.Printf("   Always sample: %v\n", rule.ForceConditions)
+		}
+		fmt.Println()
+	}
+}
+
+func deleteSampling(ctx context.Context, client pb.ControlPlaneClient, id string) {
+	resp, err := client.DeleteSamplingRule(ctx, &pb.DeleteSamplingRuleRequest{RuleId: id})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✅ Deleted: %s\n", id)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+// watchSnapshots prints a one-line summary of each snapshot for a trace as
+// it arrives. Typing a snapshot's number and Enter expands it.
+func watchSnapshots(ctx context.Context, client pb.ControlPlaneClient, traceID, service string) {
+	stream, err := client.WatchSnapshots(ctx, &pb.WatchSnapshotsRequest{TraceId: traceID, ServiceName: service})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Watching snapshots for %s (type a number and Enter to expand, Ctrl+C to stop)...\n\n", traceID)
+
+	var (
+		mu    sync.Mutex
+		snaps []*pb.Snapshot
+	)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			n, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+			mu.Lock()
+			if err != nil || n < 1 || n > len(snaps) {
+				fmt.Printf("No snapshot %q\n", scanner.Text())
+			} else {
+				printSnapshotData(snaps[n-1])
+			}
+			mu.Unlock()
+		}
+	}()
+
+	for {
+		snap, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatalf("Stream Error: %v", err)
+		}
+
+		mu.Lock()
+		snaps = append(snaps, snap)
+		fmt.Println(snapshotSummary(len(snaps), snap))
+		mu.Unlock()
+	}
+}
+
+// snapshotSummary fits a snapshot on one line: where and when it was
+// captured, and the top-level keys of its data.
+func snapshotSummary(n int, snap *pb.Snapshot) string {
+	line := snapshotHeader(n, snap)
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(snap.Data), &fields); err != nil {
+		return line + fmt.Sprintf(" %d bytes", len(snap.Data))
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return line + " {" + strings.Join(keys, ", ") + "}"
+}
+
+// snapshotHeader says where and when a snapshot was captured.
+func snapshotHeader(n int, snap *pb.Snapshot) string {
+	line := fmt.Sprintf("[%d] %s %s%s",
+		n, time.Unix(snap.CapturedAt, 0).Format("15:04:05"), snap.ServiceName, snap.Endpoint)
+	if snap.BreakpointId != "" {
+		line += " (breakpoint " + snap.BreakpointId + ")"
+	}
+	return line
+}
+
+func printSnapshotData(snap *pb.Snapshot) {
+	var data bytes.Buffer
+	if err := json.Indent(&data, []byte(snap.Data), "   ", "  "); err != nil {
+		fmt.Printf("   %s\n", snap.Data)
+		return
+	}
+	fmt.Printf("   %s\n", data.String())
+}
+
+// diffSnapshots prints, for each variable, its value in every snapshot of
+// the trace, flagging the snapshots where it changed from the one before.
+func diffSnapshots(ctx context.Context, client pb.ControlPlaneClient, traceID string, variables []string, all bool) {
+	resp, err := client.DiffSnapshots(ctx, &pb.DiffSnapshotsRequest{
+		TraceId:     traceID,
+		Variables:   variables,
+		ChangedOnly: !all,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if !resp.Success {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+		return
+	}
+
+	fmt.Printf("Trace %s: %s\n\n", resp.TraceId, resp.RespMessage)
+	for i, snap := range resp.Snapshots {
+		fmt.Println(snapshotHeader(i+1, snap))
+	}
+	if len(resp.Variables) == 0 {
+		fmt.Println("\nNo variables changed (use --all to list every variable)")
+		return
+	}
+
+	for _, v := range resp.Variables {
+		fmt.Printf("\n%s\n", v.Name)
+		for i, value := range v.Values {
+			marker := " "
+			if i > 0 && (value.Present != v.Values[i-1].Present || value.Value != v.Values[i-1].Value) {
+				marker = "≠"
+			}
+			shown := value.Value
+			if !value.Present {
+				shown = "(absent)"
+			}
+			snap := resp.Snapshots[i]
+			fmt.Printf(" %s [%d] %s%s: %s\n", marker, i+1, snap.ServiceName, snap.Endpoint, shown)
+		}
+	}
+}
+
+// listSnapshots prints one page of snapshots and how to get the next.
+func listSnapshots(ctx context.Context, client pb.ControlPlaneClient, req *pb.ListSnapshotsRequest) {
+	resp, err := client.ListSnapshots(ctx, req)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if !resp.Success {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+		return
+	}
+	if len(resp.Snapshots) == 0 {
+		fmt.Println("No snapshots")
+		return
+	}
+
+	for i, snap := range resp.Snapshots {
+		line := snapshotHeader(i+1, snap)
+		if req.TraceId == "" {
+			line += " trace " + snap.TraceId
+		}
+		if snap.Checkpoint != "" {
+			line += " @" + snap.Checkpoint
+		}
+		fmt.Println(line)
+		if req.IncludeData {
+			printSnapshotData(snap)
+		}
+	}
+	if resp.NextPageToken != "" {
+		fmt.Printf("\nMore: repeat with --page %s\n", resp.NextPageToken)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+// parseSourceLocation reads --source as file or file:line. It returns nil
+// when neither --source nor --repo is set.
+func parseSourceLocation(source, repo string) (*pb.SourceLocation, error) {
+	if source == "" {
+		if repo != "" {
+			return nil, fmt.Errorf("--repo needs --source")
+		}
+		return nil, nil
+	}
+
+	loc := &pb.SourceLocation{Repo: repo, File: source}
+	if i := strings.LastIndex(source, ":"); i >= 0 {
+		line, err := strconv.Atoi(source[i+1:])
+		if err != nil || line < 1 {
+			return nil, fmt.Errorf("invalid line in --source %q", source)
+		}
+		loc.File = source[:i]
+		loc.Line = int32(line)
+	}
+	return loc, nil
+}
+
+func sourceString(loc *pb.SourceLocation) string {
+	s := loc.File
+	if loc.Line > 0 {
+		s += fmt.Sprintf(":%d", loc.Line)
+	}
+	if loc.Repo != "" {
+		s += " in " + loc.Repo
+	}
+	return s
+}
+
+// sourceStatus says how far the control plane could check a location
+// against the deployed service.
+func sourceStatus(loc *pb.SourceLocation) string {
+	switch {
+	case loc.Verified:
+		return fmt.Sprintf(" (seen in %s)", loc.Version)
+	case loc.Version != "":
+		return fmt.Sprintf(" (not seen in %s)", loc.Version)
+	}
+	return " (unverified)"
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+func getStorageStats(ctx context.Context, client pb.ControlPlaneClient) {
+	resp, err := client.GetStorageStats(ctx, &pb.GetStorageStatsRequest{})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if resp.BudgetBytes > 0 {
+		fmt.Printf("Memory: %s of %s budget\n\n", formatBytes(resp.UsedBytes), formatBytes(resp.BudgetBytes))
+	} else {
+		fmt.Printf("Memory: %s (no budget set)\n\n", formatBytes(resp.UsedBytes))
+	}
+	fmt.Printf("   %-10s %10s %10s %6s %10s %10s\n", "STORE", "USED", "BUDGET", "", "ITEMS", "SHED")
+	for _, c := range resp.Components {
+		budget, percent := "-", ""
+		if c.BudgetBytes > 0 {
+			budget = formatBytes(c.BudgetBytes)
+			percent = fmt.Sprintf("%d%%", c.UsedBytes*100/c.BudgetBytes)
+		}
+		fmt.Printf("   %-10s %10s %10s %6s %10d %10d\n",
+			c.Name, formatBytes(c.UsedBytes), budget, percent, c.Items, c.Shed)
+	}
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
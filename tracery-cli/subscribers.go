@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+func listSubscriberClasses(ctx context.Context, client pb.ControlPlaneClient) {
+	resp, err := client.ListSubscriberClasses(ctx, &pb.ListSubscriberClassesRequest{})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Subscriber classes (%d):\n\n", len(resp.Classes))
+	fmt.Printf("   %-16s %8s  %-12s %11s %10s\n", "NAME", "BUFFER", "DROP POLICY", "SUBSCRIBERS", "DROPPED")
+	for _, class := range resp.Classes {
+		fmt.Printf("   %-16s %8d  %-12s %11d %10d\n",
+			class.Name, class.BufferSize, class.DropPolicy, class.Subscribers, class.Dropped)
+	}
+}
+
+func setSubscriberClass(ctx context.Context, client pb.ControlPlaneClient, name string, buffer int, drop string) {
+	resp, err := client.SetSubscriberClass(ctx, &pb.SetSubscriberClassRequest{
+		Name:       name,
+		BufferSize: int32(buffer),
+		DropPolicy: drop,
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if resp.Success {
+		fmt.Printf("✅ %s\n", resp.RespMessage)
+	} else {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+	}
+}
+
+// splitList parses a comma-separated flag value, ignoring empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
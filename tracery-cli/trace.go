@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+// getTrace prints a trace's spans as a tree, each with its offset from the
+// start of the trace and its duration.
+func getTrace(ctx context.Context, client pb.ControlPlaneClient, traceID string) {
+	resp, err := client.GetTrace(ctx, &pb.GetTraceRequest{TraceId: traceID})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if !resp.Success {
+		fmt.Printf("❌ %s\n", resp.RespMessage)
+		return
+	}
+
+	fmt.Printf("Trace %s: %s\n\n", resp.TraceId, resp.RespMessage)
+
+	var start int64
+	for _, root := range resp.Roots {
+		if t := root.Span.GetStartTimeUnixNano(); start == 0 || t < start {
+			start = t
+		}
+	}
+	for _, root := range resp.Roots {
+		if root.Span.GetParentSpanId() != "" {
+			fmt.Printf("(parent %s not received)\n", root.Span.GetParentSpanId())
+		}
+		printTraceSpan(root, start, 0)
+	}
+}
+
+func printTraceSpan(node *pb.TraceSpan, start int64, depth int) {
+	span := node.Span
+	offset := time.Duration(span.GetStartTimeUnixNano() - start)
+	location := node.ServiceName + node.Endpoint
+	if !strings.HasPrefix(node.Endpoint, "/") {
+		// Spans without an HTTP route are named after the operation.
+		location = node.ServiceName + " " + node.Endpoint
+	}
+	line := fmt.Sprintf("%s%s  +%s %s",
+		strings.Repeat("  ", depth), location,
+		offset.Round(time.Microsecond), spanDuration(span).Round(time.Microsecond))
+	if span.GetStatusCode() == "Error" {
+		line += " ERROR"
+		if span.GetStatusMessage() != "" {
+			line += ": " + span.GetStatusMessage()
+		}
+	}
+	fmt.Println(line)
+
+	for _, child := range node.Children {
+		printTraceSpan(child, start, depth+1)
+	}
+}
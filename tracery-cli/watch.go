@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	pb "github.com/Aneesh-Hegde/tracery/control-plane/proto/controlplane"
+)
+
+const (
+	eventTypeSpan                 = "span"
+	eventTypeBreakpointHit        = "breakpoint_hit"
+	eventTypeBreakpointRegistered = "breakpoint_registered"
+	eventTypeEventsDropped        = "events_dropped"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[1;31m"
+	colorCyan   = "\033[36m"
+	colorYellow = "\033[33m"
+	colorDim    = "\033[2m"
+	clearLine   = "\r\033[K"
+	clearScreen = "\033[H\033[2J"
+)
+
+type watchOptions struct {
+	HitsOnly  bool
+	Aggregate bool
+	NoColor   bool
+	Interval  time.Duration
+	Class     string
+}
+
+// watchStats keeps per-window counters. Rates are computed from the last
+// completed window so the numbers don't jitter while a window fills up.
+type watchStats struct {
+	window       map[string]int
+	rates        map[string]float64
+	hitWindow    map[string]int
+	hitRates     map[string]float64
+	hitTotals    map[string]int
+	hitEndpoints map[string]string
+}
+
+func newWatchStats() *watchStats {
+	return &watchStats{
+		window:       make(map[string]int),
+		rates:        make(map[string]float64),
+		hitWindow:    make(map[string]int),
+		hitRates:     make(map[string]float64),
+		hitTotals:    make(map[string]int),
+		hitEndpoints: make(map[string]string),
+	}
+}
+
+func (st *watchStats) record(event *pb.TraceEvent) {
+	switch event.EventType {
+	case eventTypeSpan, "":
+		st.window[event.ServiceName]++
+	case eventTypeBreakpointHit:
+		st.window[event.ServiceName]++
+		st.hitWindow[event.BreakpointId]++
+		st.hitTotals[event.BreakpointId]++
+		st.hitEndpoints[event.BreakpointId] = event.ServiceName + event.Endpoint
+	}
+}
+
+func (st *watchStats) roll(interval time.Duration) {
+	secs := interval.Seconds()
+	st.rates = make(map[string]float64, len(st.window))
+	for svc, n := range st.window {
+		st.rates[svc] = float64(n) / secs
+	}
+	st.hitRates = make(map[string]float64, len(st.hitWindow))
+	for bp, n := range st.hitWindow {
+		st.hitRates[bp] = float64(n) / secs
+	}
+	st.window = make(map[string]int)
+	st.hitWindow = make(map[string]int)
+}
+
+func watchTraces(ctx context.Context, client pb.ControlPlaneClient, opts watchOptions) {
+	stream, err := client.StreamTraces(ctx, &pb.StreamTracesRequest{SubscriberClass: opts.Class})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	events := make(chan *pb.TraceEvent, 100)
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				log.Fatalf("Stream Error: %v", err)
+			}
+			events <- event
+		}
+	}()
+
+	if !opts.Aggregate {
+		fmt.Println("Watching traces (Ctrl+C to stop)...")
+		fmt.Println()
+	}
+
+	color := func(code, s string) string {
+		if opts.NoColor {
+			return s
+		}
+		return code + s + colorReset
+	}
+
+	stats := newWatchStats()
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			stats.record(event)
+			if opts.Aggregate {
+				continue
+			}
+			if opts.HitsOnly && event.EventType != eventTypeBreakpointHit {
+				continue
+			}
+			fmt.Print(clearLine)
+			fmt.Println(formatEvent(event, color))
+			fmt.Print(color(colorDim, formatRates(stats.rates)))
+		case <-ticker.C:
+			stats.roll(opts.Interval)
+			if opts.Aggregate {
+				fmt.Print(clearScreen)
+				printAggregate(stats, opts.Interval, color)
+				continue
+			}
+			fmt.Print(clearLine)
+			fmt.Print(color(colorDim, formatRates(stats.rates)))
+		}
+	}
+}
+
+func formatEvent(event *pb.TraceEvent, color func(string, string) string) string {
+	ts := time.Unix(event.Timestamp, 0).Format("15:04:05")
+	switch event.EventType {
+	case eventTypeBreakpointHit:
+		return color(colorRed, fmt.Sprintf("[%s] HIT %s %s%s (breakpoint %s)",
+			ts, event.TraceId, event.ServiceName, event.Endpoint, event.BreakpointId))
+	case eventTypeSpan, "":
+		line := fmt.Sprintf("[%s] %s %s",
+			ts, event.TraceId, color(colorCyan, event.ServiceName+event.Endpoint))
+		if event.RawEndpoint != "" {
+			line += color(colorDim, " ("+event.RawEndpoint+")")
+		}
+		if span := event.Span; span != nil {
+			line += fmt.Sprintf(" %s", spanDuration(span).Round(time.Microsecond))
+			if span.StatusCode == "Error" {
+				line += color(colorRed, " ERROR")
+			}
+		}
+		return line
+	default:
+		return color(colorYellow, fmt.Sprintf("[%s] %s %s %s%s",
+			ts, strings.ToUpper(event.EventType), event.TraceId, event.ServiceName, event.Endpoint))
+	}
+}
+
+func formatRates(rates map[string]float64) string {
+	if len(rates) == 0 {
+		return "no traffic"
+	}
+	services := make([]string, 0, len(rates))
+	for svc := range rates {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+
+	parts := make([]string, 0, len(services))
+	for _, svc := range services {
+		parts = append(parts, fmt.Sprintf("%s %.1f/s", svc, rates[svc]))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func printAggregate(stats *watchStats, interval time.Duration, color func(string, string) string) {
+	fmt.Printf("Trace activity (last %s, Ctrl+C to stop)\n\n", interval)
+
+	fmt.Println("Requests/sec per service:")
+	if len(stats.rates) == 0 {
+		fmt.Println("   no traffic")
+	}
+	services := make([]string, 0, len(stats.rates))
+	for svc := range stats.rates {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+	for _, svc := range services {
+		fmt.Printf("   %-30s %8.1f\n", svc, stats.rates[svc])
+	}
+
+	fmt.Println("\nBreakpoint hits:")
+	if len(stats.hitTotals) == 0 {
+		fmt.Println("   no hits")
+	}
+	breakpoints := make([]string, 0, len(stats.hitTotals))
+	for bp := range stats.hitTotals {
+		breakpoints = append(breakpoints, bp)
+	}
+	sort.Slice(breakpoints, func(i, j int) bool {
+		return stats.hitTotals[breakpoints[i]] > stats.hitTotals[breakpoints[j]]
+	})
+	for _, bp := range breakpoints {
+		line := fmt.Sprintf("   %-36s %-30s %6d total %6.1f/s",
+			bp, stats.hitEndpoints[bp], stats.hitTotals[bp], stats.hitRates[bp])
+		if stats.hitRates[bp] > 0 {
+			line = color(colorRed, line)
+		}
+		fmt.Println(line)
+	}
+}